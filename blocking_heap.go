@@ -0,0 +1,161 @@
+package godatastructures
+
+import (
+	"context"
+	"iter"
+)
+
+// Heap which can wait for a value to be available, and optionally
+// bounded so that puts wait, or fail, while it is full.
+// Waiters are woken by closing the changed channel, which is replaced
+// on every put or removal. It is guarded by the mutex of the heap, so
+// checking the heap and taking the channel to wait on happen atomically
+type BlockingHeap[val any] struct {
+	heap     *Heap[val]
+	capacity int           // maximum number of values, unbounded if 0
+	changed  chan struct{} // closed when values are added or removed
+}
+
+// constructor
+// A capacity of 0 or less leaves the heap unbounded
+func NewBlockingHeap[val any](capacity int, f func(val, val) int) *BlockingHeap[val] {
+	b := BlockingHeap[val]{
+		heap:     NewHeap(max(capacity, 0), f),
+		capacity: max(capacity, 0),
+		changed:  make(chan struct{}),
+	}
+	return &b
+}
+
+// Return the capacity of the heap, 0 if unbounded
+func (b *BlockingHeap[val]) Cap() int {
+	return b.capacity
+}
+
+// Gets the size of the heap
+func (b *BlockingHeap[val]) Size() int {
+	return b.heap.Size()
+}
+
+// Get the item at the top of the heap without removing it
+func (b *BlockingHeap[val]) Peek() (value val, ok bool) {
+	return b.heap.Peek()
+}
+
+// Get and remove the value from the top of the heap, waiting until
+// a value is available. Returns the context's error if it is done first
+func (b *BlockingHeap[val]) Take(ctx context.Context) (value val, err error) {
+	for {
+		b.heap.mutex.Lock()
+		if len(b.heap.slice) > 0 {
+			value = b.heap.removeAt(0)
+			b.signal()
+			b.heap.mutex.Unlock()
+			return value, nil
+		}
+		changed := b.changed
+		b.heap.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return value, ctx.Err()
+		}
+	}
+}
+
+// Get and remove the value from the top of the heap without waiting
+// boolean ok indicates whether a value was available
+func (b *BlockingHeap[val]) TryTake() (value val, ok bool) {
+	b.heap.mutex.Lock()
+	defer b.heap.mutex.Unlock()
+
+	if len(b.heap.slice) == 0 {
+		return
+	}
+	value = b.heap.removeAt(0)
+	b.signal()
+	return value, true
+}
+
+// Puts the value on the heap, returning a handle to it, waiting while
+// the heap is full. Returns the context's error if it is done first
+func (b *BlockingHeap[val]) PutCtx(ctx context.Context, value val) (handle *HeapHandle[val], err error) {
+	for {
+		b.heap.mutex.Lock()
+		if !b.full() {
+			handle = b.heap.put(value)
+			b.signal()
+			b.heap.mutex.Unlock()
+			return handle, nil
+		}
+		changed := b.changed
+		b.heap.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Puts the value on the heap, returning a handle to it, without waiting
+// boolean ok is false, and the value is not added, if the heap is full
+func (b *BlockingHeap[val]) TryPut(value val) (handle *HeapHandle[val], ok bool) {
+	b.heap.mutex.Lock()
+	defer b.heap.mutex.Unlock()
+
+	if b.full() {
+		return
+	}
+	handle = b.heap.put(value)
+	b.signal()
+	return handle, true
+}
+
+// Replaces the value of the handle and moves it to its new heap location
+// Returns false if the handle is no longer on the heap
+func (b *BlockingHeap[val]) Update(handle *HeapHandle[val], value val) bool {
+	return b.heap.Update(handle, value)
+}
+
+// Moves the value of the handle to its correct heap location, after its
+// ordering has been changed in place
+// Returns false if the handle is no longer on the heap
+func (b *BlockingHeap[val]) Fix(handle *HeapHandle[val]) bool {
+	return b.heap.Fix(handle)
+}
+
+// Removes the value of the handle from the heap
+// boolean ok indicates whether the handle was still on the heap
+func (b *BlockingHeap[val]) Remove(handle *HeapHandle[val]) (value val, ok bool) {
+	b.heap.mutex.Lock()
+	defer b.heap.mutex.Unlock()
+
+	if !b.heap.holds(handle) {
+		return
+	}
+	value = b.heap.removeAt(handle.index)
+	b.signal()
+	return value, true
+}
+
+// Returns an iterator over the values of the heap in sorted order,
+// without removing them, from a copy taken when iteration starts
+func (b *BlockingHeap[val]) All() iter.Seq[val] {
+	return b.heap.All()
+}
+
+// Used internally to check whether a bounded heap is full
+// Must be called with the lock held
+func (b *BlockingHeap[val]) full() bool {
+	return b.capacity > 0 && len(b.heap.slice) >= b.capacity
+}
+
+// Used internally to wake every waiter to check the heap again
+// Must be called with the write lock held
+func (b *BlockingHeap[val]) signal() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,132 @@
+package godatastructures
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlockingHeap(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test BlockingHeap without waiting")
+	{
+		b := NewBlockingHeap(3, SortAscendingInt)
+		if b.Cap() != 3 {
+			t.Errorf("\t Cap expected 3 : %v", b.Cap())
+		}
+		if v, ok := b.TryTake(); ok {
+			t.Errorf("\t TryTake on empty heap returned a value: %v", v)
+		}
+		for _, n := range []int{3, 1, 2} {
+			if _, ok := b.TryPut(n); !ok {
+				t.Errorf("\t TryPut %v should succeed below capacity", n)
+			}
+		}
+		if _, ok := b.TryPut(0); ok {
+			t.Errorf("\t TryPut should fail on a full heap")
+		}
+		if v, ok := b.TryTake(); !ok || v != 1 {
+			t.Errorf("\t TryTake expected 1 : %v %v", v, ok)
+		}
+		handle, ok := b.TryPut(5)
+		if !ok {
+			t.Errorf("\t TryPut should succeed after TryTake")
+		}
+		b.Update(handle, 0)
+		if values := slices.Collect(b.All()); !slices.Equal(values, []int{0, 2, 3}) {
+			t.Errorf("\t All expected [0 2 3] : %v", values)
+		}
+		if v, ok := b.Remove(handle); !ok || v != 0 {
+			t.Errorf("\t Remove expected 0 : %v %v", v, ok)
+		}
+		if b.Size() != 2 {
+			t.Errorf("\t Size expected 2 : %v", b.Size())
+		}
+	}
+
+	t.Log("Given the need to test BlockingHeap cancellation")
+	{
+		b := NewBlockingHeap(1, SortAscendingInt)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := b.Take(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("\t Take on empty heap expected deadline exceeded : %v", err)
+		}
+
+		b.TryPut(1)
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		if _, err := b.PutCtx(ctx, 2); !errors.Is(err, context.Canceled) {
+			t.Errorf("\t PutCtx on full heap expected cancelled : %v", err)
+		}
+		if b.Size() != 1 {
+			t.Errorf("\t PutCtx should not add after cancellation : %v", b.Size())
+		}
+	}
+
+	t.Log("Given the need to test BlockingHeap producers and consumers")
+	{
+		b := NewBlockingHeap(4, SortAscendingInt)
+		ctx := context.Background()
+
+		producers := sync.WaitGroup{}
+		for p := range 4 {
+			producers.Add(1)
+			go func() {
+				defer producers.Done()
+				for n := range 100 {
+					if _, err := b.PutCtx(ctx, p*100+n); err != nil {
+						t.Errorf("\t PutCtx failed : %v", err)
+					}
+				}
+			}()
+		}
+
+		taken := make(chan int, 400)
+		consumers := sync.WaitGroup{}
+		for range 3 {
+			consumers.Add(1)
+			go func() {
+				defer consumers.Done()
+				for {
+					v, err := b.Take(ctx)
+					if err != nil {
+						t.Errorf("\t Take failed : %v", err)
+						return
+					}
+					if v == math.MaxInt {
+						return
+					}
+					taken <- v
+				}
+			}()
+		}
+
+		producers.Wait()
+		for range 3 {
+			b.PutCtx(ctx, math.MaxInt) // sorts last, stopping a consumer once the rest are taken
+		}
+		consumers.Wait()
+		close(taken)
+
+		values := make([]int, 0, 400)
+		for v := range taken {
+			values = append(values, v)
+		}
+		slices.Sort(values)
+		for i, v := range values {
+			if v != i {
+				t.Errorf("\t Consumers expected every value once, missing %v", i)
+				break
+			}
+		}
+		if len(values) != 400 {
+			t.Errorf("\t Consumers expected 400 values : %v", len(values))
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package godatastructures
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// Decides which keys a Cache holds. The cache calls the policy under its
+// lock, so policies need no locking of their own, and each policy
+// instance must only be used by a single cache
+type EvictionPolicy[key comparable] interface {
+	// Records a hit on a key held by the cache
+	Hit(k key)
+	// Admits a key not held by the cache. If the cache is then over
+	// capacity, returns the key to evict, which may be k itself if
+	// the policy declines to admit it
+	Add(k key) (evicted key, ok bool)
+	// Forgets a key removed from the cache other than by eviction
+	Remove(k key)
+	// Forgets every key
+	Clear()
+	// Returns the maximum number of keys held
+	Cap() int
+}
+
+// Returned by NewCache when an argument has an invalid value
+var ErrInvalidCacheOption = errors.New("cache: invalid option")
+
+// Counts of the lookups made by Get
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// Returns the fraction of lookups which were hits, 0 if there were none
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Cache of bounded size with a pluggable EvictionPolicy choosing which
+// keys to hold. Values leaving the cache are passed to the OnEvict hook,
+// if set, after the cache lock is released, so the hook may use the cache
+type Cache[key comparable, val any] struct {
+	mutex   sync.Mutex // exclusive for reads too, as policies record hits
+	values  map[key]val
+	policy  EvictionPolicy[key]
+	stats   CacheStats
+	onEvict func(k key, v val, reason EvictReason)
+}
+
+// constructor
+// The policy must not be used by another cache
+// Returns an error wrapping ErrInvalidCacheOption if the policy is nil
+func NewCache[key comparable, val any](policy EvictionPolicy[key]) (*Cache[key, val], error) {
+	if policy == nil {
+		return nil, fmt.Errorf("%w: policy must not be nil", ErrInvalidCacheOption)
+	}
+
+	c := Cache[key, val]{
+		values: make(map[key]val, policy.Cap()),
+		policy: policy,
+	}
+	return &c, nil
+}
+
+// Return the capacity of the cache
+func (c *Cache[key, val]) Cap() int {
+	return c.policy.Cap()
+}
+
+// Return the current length of the cache
+func (c *Cache[key, val]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.values)
+}
+
+// Check if the key is in the cache.
+// Does not count as a hit or miss, or affect eviction
+func (c *Cache[key, val]) Contains(k key) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, ok := c.values[k]
+	return ok
+}
+
+// Return the value associated with the key, recording a hit or miss
+// boolean ok indicates presence of a value
+func (c *Cache[key, val]) Get(k key) (value val, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	value, ok = c.values[k]
+	if !ok {
+		c.stats.Misses++
+		return
+	}
+	c.stats.Hits++
+	c.policy.Hit(k)
+	return
+}
+
+// Put the value into the cache with the supplied key, replacing the
+// value for the key as needed, which counts as a hit on the key.
+// A new key may cause another key, or itself, to be evicted
+func (c *Cache[key, val]) Put(k key, v val) {
+	c.mutex.Lock()
+	onEvict := c.onEvict
+
+	if old, present := c.values[k]; present {
+		c.values[k] = v
+		c.policy.Hit(k)
+		c.mutex.Unlock()
+
+		notify(onEvict, eviction[key, val]{k, old, EvictReplaced})
+		return
+	}
+
+	c.values[k] = v
+	evicted, ok := c.policy.Add(k)
+	if !ok {
+		c.mutex.Unlock()
+		return
+	}
+	value := c.values[evicted]
+	delete(c.values, evicted)
+	c.mutex.Unlock()
+
+	notify(onEvict, eviction[key, val]{evicted, value, EvictCapacity})
+}
+
+// Remove the key from the cache
+// boolean ok indicates whether a value was present
+func (c *Cache[key, val]) Remove(k key) (ok bool) {
+	c.mutex.Lock()
+	value, ok := c.values[k]
+	if !ok {
+		c.mutex.Unlock()
+		return false
+	}
+	delete(c.values, k)
+	c.policy.Remove(k)
+	onEvict := c.onEvict
+	c.mutex.Unlock()
+
+	notify(onEvict, eviction[key, val]{k, value, EvictRemoved})
+	return true
+}
+
+// Clear all values from the cache. The statistics are kept
+func (c *Cache[key, val]) Clear() {
+	c.mutex.Lock()
+	evictions := []eviction[key, val]{}
+	if c.onEvict != nil {
+		for k, v := range c.values {
+			evictions = append(evictions, eviction[key, val]{k, v, EvictCleared})
+		}
+	}
+	clear(c.values)
+	c.policy.Clear()
+	onEvict := c.onEvict
+	c.mutex.Unlock()
+
+	notify(onEvict, evictions...)
+}
+
+// Set the function called with each key and value leaving the cache,
+// and the reason, or nil for none. It is called without the cache lock
+// held, once the operation evicting the value has completed
+func (c *Cache[key, val]) OnEvict(f func(k key, v val, reason EvictReason)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onEvict = f
+}
+
+// Return the counts of hits and misses recorded by Get
+func (c *Cache[key, val]) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.stats
+}
+
+// Returns an iterator over the keys and values in the cache, in no
+// particular order, copied under the lock when iteration starts.
+// Does not affect eviction
+func (c *Cache[key, val]) All() iter.Seq2[key, val] {
+	return func(yield func(key, val) bool) {
+		c.mutex.Lock()
+		entries := make([]MapEntry[key, val], 0, len(c.values))
+		for k, v := range c.values {
+			entries = append(entries, MapEntry[key, val]{key: k, value: v})
+		}
+		c.mutex.Unlock()
+
+		for _, e := range entries {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,697 @@
+package godatastructures
+
+import "math/bits"
+
+// Checks the built in policies satisfy the interface
+var (
+	_ EvictionPolicy[int] = (*LRUPolicy[int])(nil)
+	_ EvictionPolicy[int] = (*LFUPolicy[int])(nil)
+	_ EvictionPolicy[int] = (*ARCPolicy[int])(nil)
+	_ EvictionPolicy[int] = (*TwoQueuePolicy[int])(nil)
+	_ EvictionPolicy[int] = (*TinyLFUPolicy[int])(nil)
+	_ EvictionPolicy[int] = (*SievePolicy[int])(nil)
+)
+
+// Evicts the least recently used key, as LRUCache does
+type LRUPolicy[key comparable] struct {
+	capacity int
+	recency  *keyList[key] // most recent first
+}
+
+// constructor
+// A capacity below 1 is treated as 1
+func NewLRUPolicy[key comparable](capacity int) *LRUPolicy[key] {
+	p := LRUPolicy[key]{
+		capacity: max(capacity, 1),
+		recency:  newKeyList[key](),
+	}
+	return &p
+}
+
+// Return the maximum number of keys held, as for EvictionPolicy
+func (p *LRUPolicy[key]) Cap() int {
+	return p.capacity
+}
+
+// Move the key to the most recent position
+func (p *LRUPolicy[key]) Hit(k key) {
+	p.recency.toFront(k)
+}
+
+// Forget the key
+func (p *LRUPolicy[key]) Remove(k key) {
+	p.recency.remove(k)
+}
+
+// Forget every key
+func (p *LRUPolicy[key]) Clear() {
+	p.recency.clear()
+}
+
+// Admit the key as the most recent, evicting the least recent key
+// if over capacity
+func (p *LRUPolicy[key]) Add(k key) (evicted key, ok bool) {
+	p.recency.pushFront(k)
+	if p.recency.len() > p.capacity {
+		return p.recency.popBack()
+	}
+	return
+}
+
+// Evicts the least frequently used key, and the least recently used of
+// those on a tie. Keys are kept in a list per use count, so each
+// operation is O(1)
+type LFUPolicy[key comparable] struct {
+	capacity int
+	entries  map[key]*lfuEntry[key]
+	counts   map[int]*List[key] // keys by use count, most recent first
+	minCount int                // may be stale after Remove, found again on eviction
+}
+
+// A key in an LFUPolicy, with the node holding it in its count's list
+type lfuEntry[key comparable] struct {
+	node  *Node[key]
+	count int
+}
+
+// constructor
+// A capacity below 1 is treated as 1
+func NewLFUPolicy[key comparable](capacity int) *LFUPolicy[key] {
+	p := LFUPolicy[key]{
+		capacity: max(capacity, 1),
+		entries:  make(map[key]*lfuEntry[key]),
+		counts:   make(map[int]*List[key]),
+	}
+	return &p
+}
+
+// Return the maximum number of keys held, as for EvictionPolicy
+func (p *LFUPolicy[key]) Cap() int {
+	return p.capacity
+}
+
+// Count a use of the key, moving it to the list of its new count
+func (p *LFUPolicy[key]) Hit(k key) {
+	e, ok := p.entries[k]
+	if !ok {
+		return
+	}
+	p.unlink(e)
+	if p.minCount == e.count && p.counts[e.count] == nil {
+		p.minCount++
+	}
+	e.count++
+	e.node = p.link(k, e.count)
+}
+
+// Admit the key with a use count of one, first evicting the least
+// frequently used key if at capacity
+func (p *LFUPolicy[key]) Add(k key) (evicted key, ok bool) {
+	if len(p.entries) >= p.capacity {
+		if _, present := p.counts[p.minCount]; !present {
+			p.minCount = p.lowestCount()
+		}
+		least := p.counts[p.minCount]
+		evicted, ok = least.RemoveLast()
+		if least.Size() == 0 {
+			delete(p.counts, p.minCount)
+		}
+		delete(p.entries, evicted)
+	}
+
+	p.entries[k] = &lfuEntry[key]{node: p.link(k, 1), count: 1}
+	p.minCount = 1
+	return
+}
+
+// Forget the key and its use count
+func (p *LFUPolicy[key]) Remove(k key) {
+	if e, ok := p.entries[k]; ok {
+		p.unlink(e)
+		delete(p.entries, k)
+	}
+}
+
+// Forget every key and use count
+func (p *LFUPolicy[key]) Clear() {
+	clear(p.entries)
+	clear(p.counts)
+	p.minCount = 0
+}
+
+// Used internally to add the key to the front of its count's list
+func (p *LFUPolicy[key]) link(k key, count int) *Node[key] {
+	l, ok := p.counts[count]
+	if !ok {
+		l = NewList[key]()
+		p.counts[count] = l
+	}
+	l.AddFirst(k)
+	return l.First()
+}
+
+// Used internally to remove the entry from its count's list,
+// dropping the list if it is left empty
+func (p *LFUPolicy[key]) unlink(e *lfuEntry[key]) {
+	l := p.counts[e.count]
+	l.Unlink(e.node)
+	if l.Size() == 0 {
+		delete(p.counts, e.count)
+	}
+}
+
+// Used internally to find the lowest use count of any key
+func (p *LFUPolicy[key]) lowestCount() int {
+	lowest := 0
+	for count := range p.counts {
+		if lowest == 0 || count < lowest {
+			lowest = count
+		}
+	}
+	return lowest
+}
+
+// Adaptive Replacement Cache. Keys seen once and keys seen again are
+// held in separate LRU lists, with ghost lists remembering recently
+// evicted keys of each. A miss on a ghost key shifts the target size of
+// the lists towards the one it was evicted from, so the policy adapts
+// between recency and frequency as the workload changes
+type ARCPolicy[key comparable] struct {
+	capacity int
+	target   int           // the target size of recent
+	recent   *keyList[key] // held keys seen once, T1
+	frequent *keyList[key] // held keys seen more than once, T2
+	ghostR   *keyList[key] // keys evicted from recent, B1
+	ghostF   *keyList[key] // keys evicted from frequent, B2
+}
+
+// constructor
+// A capacity below 1 is treated as 1
+func NewARCPolicy[key comparable](capacity int) *ARCPolicy[key] {
+	p := ARCPolicy[key]{
+		capacity: max(capacity, 1),
+		recent:   newKeyList[key](),
+		frequent: newKeyList[key](),
+		ghostR:   newKeyList[key](),
+		ghostF:   newKeyList[key](),
+	}
+	return &p
+}
+
+// Return the maximum number of keys held, as for EvictionPolicy
+func (p *ARCPolicy[key]) Cap() int {
+	return p.capacity
+}
+
+// Move the key to the front of frequent, from recent if seen once
+func (p *ARCPolicy[key]) Hit(k key) {
+	if p.recent.remove(k) {
+		p.frequent.pushFront(k)
+		return
+	}
+	p.frequent.toFront(k)
+}
+
+// Admit the key to recent, or to frequent if it is a ghost, adapting
+// the target size of recent towards the ghost list it was found in
+func (p *ARCPolicy[key]) Add(k key) (evicted key, ok bool) {
+	switch {
+	case p.ghostR.contains(k):
+		p.target = min(p.capacity, p.target+max(1, p.ghostF.len()/p.ghostR.len()))
+		evicted, ok = p.replace(false)
+		p.ghostR.remove(k)
+		p.frequent.pushFront(k)
+		return
+
+	case p.ghostF.contains(k):
+		p.target = max(0, p.target-max(1, p.ghostR.len()/p.ghostF.len()))
+		evicted, ok = p.replace(true)
+		p.ghostF.remove(k)
+		p.frequent.pushFront(k)
+		return
+	}
+
+	seenOnce := p.recent.len() + p.ghostR.len()
+	total := seenOnce + p.frequent.len() + p.ghostF.len()
+	switch {
+	case seenOnce >= p.capacity && p.recent.len() >= p.capacity:
+		// every key seen once is held, so the oldest is dropped outright
+		evicted, ok = p.recent.popBack()
+	case seenOnce >= p.capacity:
+		p.ghostR.popBack()
+		evicted, ok = p.replace(false)
+	case total >= p.capacity:
+		if total >= 2*p.capacity {
+			p.ghostF.popBack()
+		}
+		evicted, ok = p.replace(false)
+	}
+	p.recent.pushFront(k)
+	return
+}
+
+// Forget the key, without remembering it as a ghost
+func (p *ARCPolicy[key]) Remove(k key) {
+	if !p.recent.remove(k) {
+		p.frequent.remove(k)
+	}
+}
+
+// Forget every key, ghosts included, and reset the target size
+func (p *ARCPolicy[key]) Clear() {
+	p.target = 0
+	p.recent.clear()
+	p.frequent.clear()
+	p.ghostR.clear()
+	p.ghostF.clear()
+}
+
+// Used internally to evict a held key into its ghost list, if the cache
+// is full, from recent if it is over its target size, else from frequent
+func (p *ARCPolicy[key]) replace(ghostF bool) (evicted key, ok bool) {
+	if p.recent.len()+p.frequent.len() < p.capacity {
+		return
+	}
+	over := p.recent.len() > p.target || (ghostF && p.recent.len() == p.target)
+	if p.recent.len() > 0 && (over || p.frequent.len() == 0) {
+		evicted, ok = p.recent.popBack()
+		p.ghostR.pushFront(evicted)
+		return
+	}
+	evicted, ok = p.frequent.popBack()
+	p.ghostF.pushFront(evicted)
+	return
+}
+
+// The full 2Q policy. New keys enter a FIFO queue, and move to the main
+// LRU list only if seen again after leaving it, while a ghost queue
+// remembers recently evicted new keys. A scan of keys seen once cycles
+// through the FIFO queue without displacing keys in the main list
+type TwoQueuePolicy[key comparable] struct {
+	capacity int
+	inCap    int           // the size the in queue is kept to when evicting
+	outCap   int           // the maximum size of the out queue
+	in       *keyList[key] // held keys seen once, A1in
+	out      *keyList[key] // keys evicted from in, A1out
+	main     *keyList[key] // held keys seen again, Am
+}
+
+// constructor
+// A capacity below 1 is treated as 1. The in queue is kept to a
+// quarter of the capacity, and the ghost queue to a half
+func NewTwoQueuePolicy[key comparable](capacity int) *TwoQueuePolicy[key] {
+	capacity = max(capacity, 1)
+	p := TwoQueuePolicy[key]{
+		capacity: capacity,
+		inCap:    max(capacity/4, 1),
+		outCap:   max(capacity/2, 1),
+		in:       newKeyList[key](),
+		out:      newKeyList[key](),
+		main:     newKeyList[key](),
+	}
+	return &p
+}
+
+// Return the maximum number of keys held, as for EvictionPolicy
+func (p *TwoQueuePolicy[key]) Cap() int {
+	return p.capacity
+}
+
+// Move a key in the main list to its front.
+// Keys in the in queue are not moved on a hit, so a burst of
+// accesses does not count as the key being seen again
+func (p *TwoQueuePolicy[key]) Hit(k key) {
+	p.main.toFront(k)
+}
+
+// Admit the key to the in queue, or to the main list if it was
+// recently evicted from the in queue
+func (p *TwoQueuePolicy[key]) Add(k key) (evicted key, ok bool) {
+	if p.out.remove(k) {
+		p.main.pushFront(k)
+	} else {
+		p.in.pushFront(k)
+	}
+
+	if p.in.len()+p.main.len() <= p.capacity {
+		return
+	}
+	if p.in.len() > p.inCap || p.main.len() == 0 {
+		evicted, ok = p.in.popBack()
+		p.out.pushFront(evicted)
+		if p.out.len() > p.outCap {
+			p.out.popBack()
+		}
+		return
+	}
+	return p.main.popBack()
+}
+
+// Forget the key, without remembering it in the out queue
+func (p *TwoQueuePolicy[key]) Remove(k key) {
+	if !p.in.remove(k) {
+		p.main.remove(k)
+	}
+}
+
+// Forget every key, including those in the out queue
+func (p *TwoQueuePolicy[key]) Clear() {
+	p.in.clear()
+	p.out.clear()
+	p.main.clear()
+}
+
+// Window TinyLFU. New keys enter a small LRU window, and on leaving it
+// are admitted to the main segmented LRU only if they have been used
+// more often than the key the main area would evict. Use counts are
+// approximated by a count-min sketch, halved periodically so old
+// popularity fades. The window lets bursts of new keys build up counts
+// before competing with established ones
+type TinyLFUPolicy[key comparable] struct {
+	capacity     int
+	windowCap    int
+	protectedCap int
+	window       *keyList[key] // newly added keys, 1% of the capacity
+	probation    *keyList[key] // main keys not hit since admission
+	protected    *keyList[key] // main keys hit since admission, 80% of the main area
+	sketch       *countMinSketch[key]
+}
+
+// constructor
+// A capacity below 1 is treated as 1
+func NewTinyLFUPolicy[key comparable](capacity int) *TinyLFUPolicy[key] {
+	capacity = max(capacity, 1)
+	windowCap := max(capacity/100, 1)
+	p := TinyLFUPolicy[key]{
+		capacity:     capacity,
+		windowCap:    windowCap,
+		protectedCap: (capacity - windowCap) * 8 / 10,
+		window:       newKeyList[key](),
+		probation:    newKeyList[key](),
+		protected:    newKeyList[key](),
+		sketch:       newCountMinSketch[key](capacity),
+	}
+	return &p
+}
+
+// Return the maximum number of keys held, as for EvictionPolicy
+func (p *TinyLFUPolicy[key]) Cap() int {
+	return p.capacity
+}
+
+// Count a use of the key, promoting it to protected if on probation
+func (p *TinyLFUPolicy[key]) Hit(k key) {
+	p.sketch.increment(k)
+
+	switch {
+	case p.window.toFront(k), p.protected.toFront(k):
+	case p.probation.remove(k):
+		p.protected.pushFront(k)
+		if p.protected.len() > p.protectedCap {
+			demoted, _ := p.protected.popBack()
+			p.probation.pushFront(demoted)
+		}
+	}
+}
+
+// Admit the key to the window. The key leaving the window replaces the
+// main area's victim only if it has been used more often
+func (p *TinyLFUPolicy[key]) Add(k key) (evicted key, ok bool) {
+	p.sketch.increment(k)
+	p.window.pushFront(k)
+	if p.window.len() <= p.windowCap {
+		return
+	}
+
+	candidate, _ := p.window.popBack()
+	if p.window.len()+p.probation.len()+p.protected.len() < p.capacity {
+		p.probation.pushFront(candidate)
+		return
+	}
+
+	victims := p.probation
+	if victims.len() == 0 {
+		victims = p.protected
+	}
+	victim, present := victims.back()
+	if !present || p.sketch.estimate(candidate) <= p.sketch.estimate(victim) {
+		return candidate, true
+	}
+	victims.remove(victim)
+	p.probation.pushFront(candidate)
+	return victim, true
+}
+
+// Forget the key, keeping its count in the sketch
+func (p *TinyLFUPolicy[key]) Remove(k key) {
+	if !p.window.remove(k) && !p.probation.remove(k) {
+		p.protected.remove(k)
+	}
+}
+
+// Forget every key. The sketch is kept, as the popularity of keys
+// outlives their values
+func (p *TinyLFUPolicy[key]) Clear() {
+	p.window.clear()
+	p.probation.clear()
+	p.protected.clear()
+}
+
+// SIEVE. Keys are held in insertion order and a hit only marks the key
+// as visited, so hits need no reordering. To evict, a hand moves from
+// the oldest key towards the newest, clearing visited marks, and evicts
+// the first key not visited, continuing from there the next time
+type SievePolicy[key comparable] struct {
+	capacity int
+	queue    *List[sieveEntry[key]] // newest first
+	nodes    map[key]*Node[sieveEntry[key]]
+	hand     *Node[sieveEntry[key]] // the next key to check, nil for the oldest
+}
+
+// A key in a SievePolicy and whether it has been hit since the hand passed
+type sieveEntry[key comparable] struct {
+	key     key
+	visited bool
+}
+
+// constructor
+// A capacity below 1 is treated as 1
+func NewSievePolicy[key comparable](capacity int) *SievePolicy[key] {
+	p := SievePolicy[key]{
+		capacity: max(capacity, 1),
+		queue:    NewList[sieveEntry[key]](),
+		nodes:    make(map[key]*Node[sieveEntry[key]]),
+	}
+	return &p
+}
+
+// Return the maximum number of keys held, as for EvictionPolicy
+func (p *SievePolicy[key]) Cap() int {
+	return p.capacity
+}
+
+// Mark the key as visited, without moving it
+func (p *SievePolicy[key]) Hit(k key) {
+	if n, ok := p.nodes[k]; ok {
+		n.value.visited = true
+	}
+}
+
+// Admit the key as the newest, first evicting the next key the hand
+// finds not visited if at capacity
+func (p *SievePolicy[key]) Add(k key) (evicted key, ok bool) {
+	if len(p.nodes) >= p.capacity {
+		evicted, ok = p.evict()
+	}
+	p.queue.AddFirst(sieveEntry[key]{key: k})
+	p.nodes[k] = p.queue.First()
+	return
+}
+
+// Forget the key, moving the hand past it if needed
+func (p *SievePolicy[key]) Remove(k key) {
+	if n, ok := p.nodes[k]; ok {
+		p.unlink(n)
+	}
+}
+
+// Forget every key and reset the hand
+func (p *SievePolicy[key]) Clear() {
+	p.queue.Clear()
+	clear(p.nodes)
+	p.hand = nil
+}
+
+// Used internally to move the hand to the first key not visited,
+// clearing marks on the way, and evict it
+func (p *SievePolicy[key]) evict() (evicted key, ok bool) {
+	n := p.hand
+	if n == nil {
+		n = p.queue.Last()
+	}
+	for n.value.visited {
+		n.value.visited = false
+		if n = n.prev; n == nil {
+			n = p.queue.Last()
+		}
+	}
+	p.hand = n // unlinking moves the hand on to the next newest key
+	p.unlink(n)
+	return n.value.key, true
+}
+
+// Used internally to remove a node, moving the hand past it if needed
+func (p *SievePolicy[key]) unlink(n *Node[sieveEntry[key]]) {
+	if p.hand == n {
+		p.hand = n.prev
+	}
+	p.queue.Unlink(n)
+	delete(p.nodes, n.value.key)
+}
+
+// List of keys with an index, so a key can be found, moved or removed
+// in O(1). Used by the policies, which are called under the cache lock
+type keyList[key comparable] struct {
+	list  List[key] // front first
+	nodes map[key]*Node[key]
+}
+
+// constructor
+func newKeyList[key comparable]() *keyList[key] {
+	l := keyList[key]{
+		nodes: make(map[key]*Node[key]),
+	}
+	return &l
+}
+
+func (l *keyList[key]) len() int {
+	return len(l.nodes)
+}
+
+func (l *keyList[key]) contains(k key) bool {
+	_, ok := l.nodes[k]
+	return ok
+}
+
+// Adds a key not already in the list at the front
+func (l *keyList[key]) pushFront(k key) {
+	l.list.AddFirst(k)
+	l.nodes[k] = l.list.First()
+}
+
+// Moves the key to the front, returning false if it is not in the list
+func (l *keyList[key]) toFront(k key) bool {
+	n, ok := l.nodes[k]
+	if ok {
+		l.list.ToFirst(n)
+	}
+	return ok
+}
+
+// Removes the key, returning false if it is not in the list
+func (l *keyList[key]) remove(k key) bool {
+	n, ok := l.nodes[k]
+	if ok {
+		l.list.Unlink(n)
+		delete(l.nodes, k)
+	}
+	return ok
+}
+
+// Returns the key at the back without removing it
+func (l *keyList[key]) back() (k key, ok bool) {
+	return l.list.PeekLast()
+}
+
+// Removes and returns the key at the back
+func (l *keyList[key]) popBack() (k key, ok bool) {
+	k, ok = l.list.RemoveLast()
+	if ok {
+		delete(l.nodes, k)
+	}
+	return
+}
+
+func (l *keyList[key]) clear() {
+	l.list.Clear()
+	clear(l.nodes)
+}
+
+// Approximate counts of key uses in four rows of 4 bit counters, each
+// row indexed by a different hash of the key. A key's count is the
+// smallest of its counters, which only overestimates when every one is
+// shared with other keys. Every counter is halved once the number of
+// increments reaches ten times the capacity, so counts reflect recent use
+type countMinSketch[key comparable] struct {
+	rows      [4][]uint8
+	hasher    Hasher[key]
+	shift     uint // 64 less the number of bits indexing a row
+	additions int
+	resetAt   int
+}
+
+// constructor, sized for a cache of the capacity with a power of two
+// at least four times as many counters per row, to limit collisions
+func newCountMinSketch[key comparable](capacity int) *countMinSketch[key] {
+	width := 1 << bits.Len(uint(4*max(capacity, 4)-1))
+	s := countMinSketch[key]{
+		hasher:  hash[key],
+		shift:   uint(64 - bits.TrailingZeros(uint(width))),
+		resetAt: 10 * capacity,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return &s
+}
+
+// Counts a use of the key, saturating at 15
+func (s *countMinSketch[key]) increment(k key) {
+	h := s.hasher(k)
+	for i := range s.rows {
+		if c := &s.rows[i][s.index(h, i)]; *c < 15 {
+			*c++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.age()
+	}
+}
+
+// Returns the approximate number of uses of the key
+func (s *countMinSketch[key]) estimate(k key) uint8 {
+	h := s.hasher(k)
+	lowest := uint8(15)
+	for i := range s.rows {
+		lowest = min(lowest, s.rows[i][s.index(h, i)])
+	}
+	return lowest
+}
+
+// Odd multipliers remixing the hash for each row of a countMinSketch
+var sketchSeeds = [4]uint64{
+	0x9e3779b97f4a7c15,
+	0xbf58476d1ce4e5b9,
+	0x94d049bb133111eb,
+	0xd6e8feb86659fd93,
+}
+
+// Used internally to find the counter for a hash in a row, from the high
+// bits of the hash multiplied by the row's seed. Every bit of the hash
+// reaches the high bits, so keys sharing a counter in one row are
+// unlikely to share one in the others
+func (s *countMinSketch[key]) index(h uint64, row int) uint64 {
+	return (h * sketchSeeds[row]) >> s.shift
+}
+
+// Used internally to halve every counter
+func (s *countMinSketch[key]) age() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,319 @@
+package godatastructures
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// The built in eviction policies, for tests and benchmarks
+var cachePolicies = []struct {
+	name   string
+	policy func(capacity int) EvictionPolicy[int]
+}{
+	{name: "LRU", policy: func(c int) EvictionPolicy[int] { return NewLRUPolicy[int](c) }},
+	{name: "LFU", policy: func(c int) EvictionPolicy[int] { return NewLFUPolicy[int](c) }},
+	{name: "ARC", policy: func(c int) EvictionPolicy[int] { return NewARCPolicy[int](c) }},
+	{name: "2Q", policy: func(c int) EvictionPolicy[int] { return NewTwoQueuePolicy[int](c) }},
+	{name: "W-TinyLFU", policy: func(c int) EvictionPolicy[int] { return NewTinyLFUPolicy[int](c) }},
+	{name: "SIEVE", policy: func(c int) EvictionPolicy[int] { return NewSievePolicy[int](c) }},
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test Cache with each eviction policy")
+	{
+		for i, test := range cachePolicies {
+			t.Logf("\tTest: %d\t When testing the %s policy", i, test.name)
+			{
+				c, _ := NewCache[int, string](test.policy(4))
+				if c.Cap() != 4 {
+					t.Errorf("\t%d\t Cap expected 4 : %v", i, c.Cap())
+				}
+				if v, ok := c.Get(1); ok {
+					t.Errorf("\t%d\t Get on empty cache returned a value: %v", i, v)
+				}
+
+				c.Put(1, "one")
+				c.Put(1, "uno")
+				if v, ok := c.Get(1); !ok || v != "uno" {
+					t.Errorf("\t%d\t Get after replacing expected uno : %v %v", i, v, ok)
+				}
+				if !c.Contains(1) || c.Contains(2) {
+					t.Errorf("\t%d\t Contains unexpected", i)
+				}
+				if !c.Remove(1) || c.Remove(1) || c.Len() != 0 {
+					t.Errorf("\t%d\t Remove should remove the key once", i)
+				}
+				if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.HitRatio() != 0.5 {
+					t.Errorf("\t%d\t Stats expected 1 hit and 1 miss : %+v", i, stats)
+				}
+
+				t.Logf("\t%d\t Testing random operations keep the cache consistent", i)
+
+				held := map[int]bool{}
+				c.OnEvict(func(k int, v string, reason EvictReason) {
+					if reason == EvictCapacity {
+						if !held[k] {
+							t.Errorf("\t%d\t Evicted key %v was not held", i, k)
+						}
+						delete(held, k)
+					}
+				})
+				r := rand.New(rand.NewSource(int64(i)))
+				for n := range 5000 {
+					k := r.Intn(20)
+					switch r.Intn(10) {
+					case 0:
+						if c.Remove(k) != held[k] {
+							t.Errorf("\t%d\t Remove of %v disagreed with the keys held", i, k)
+						}
+						delete(held, k)
+					case 1, 2, 3:
+						if _, ok := c.Get(k); ok != held[k] {
+							t.Errorf("\t%d\t Get of %v disagreed with the keys held", i, k)
+						}
+					default:
+						held[k] = true
+						c.Put(k, "value")
+					}
+					if n%1000 == 999 {
+						c.Clear()
+						clear(held)
+					}
+					if c.Len() > c.Cap() || c.Len() != len(held) {
+						t.Fatalf("\t%d\t Len %v should match the %v keys held, within capacity", i, c.Len(), len(held))
+					}
+				}
+
+				keys := []int{}
+				for k := range c.All() {
+					keys = append(keys, k)
+				}
+				for k := range held {
+					if !slices.Contains(keys, k) {
+						t.Errorf("\t%d\t All missing held key %v", i, k)
+					}
+				}
+			}
+		}
+	}
+
+	t.Log("Given the need to test Cache with no policy")
+	{
+		if _, err := NewCache[int, int](nil); !errors.Is(err, ErrInvalidCacheOption) {
+			t.Errorf("\t Nil policy expected ErrInvalidCacheOption : %v", err)
+		}
+	}
+}
+
+func TestCachePolicyBehaviour(t *testing.T) {
+	t.Parallel()
+
+	// fills a cache of 4 with 1 to 4, hits on the keys, puts 5
+	// and returns the keys still held
+	held := func(policy EvictionPolicy[int], hits ...int) []int {
+		c, _ := NewCache[int, int](policy)
+		for k := 1; k <= 4; k++ {
+			c.Put(k, k)
+		}
+		for _, k := range hits {
+			c.Get(k)
+		}
+		c.Put(5, 5)
+		keys := []int{}
+		for k := range c.All() {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+
+	t.Log("Given the need to test which key each policy evicts")
+	{
+		if keys := held(NewLRUPolicy[int](4), 1); !slices.Equal(keys, []int{1, 3, 4, 5}) {
+			t.Errorf("\t LRU should evict the least recent : %v", keys)
+		}
+		if keys := held(NewLFUPolicy[int](4), 1, 1, 2, 3, 4); !slices.Equal(keys, []int{1, 3, 4, 5}) {
+			t.Errorf("\t LFU should evict the least recent of the least used : %v", keys)
+		}
+		if keys := held(NewSievePolicy[int](4), 1, 3); !slices.Equal(keys, []int{1, 3, 4, 5}) {
+			t.Errorf("\t SIEVE should evict the oldest key not visited : %v", keys)
+		}
+		if keys := held(NewARCPolicy[int](4), 1, 2); !slices.Equal(keys, []int{1, 2, 4, 5}) {
+			t.Errorf("\t ARC should evict from keys seen once : %v", keys)
+		}
+		if keys := held(NewTwoQueuePolicy[int](4)); !slices.Equal(keys, []int{2, 3, 4, 5}) {
+			t.Errorf("\t 2Q should evict the oldest new key : %v", keys)
+		}
+	}
+
+	t.Log("Given the need to test scan resistance")
+	{
+		for _, test := range cachePolicies {
+			if test.name == "LRU" {
+				continue
+			}
+			c, _ := NewCache[int, int](test.policy(100))
+			// a hot set read through the cache, alongside keys used once,
+			// then interrupted by a long scan
+			readThrough := func(k int) {
+				if _, ok := c.Get(k); !ok {
+					c.Put(k, k)
+				}
+			}
+			for round := range 10 {
+				for k := range 50 {
+					readThrough(k)
+					readThrough(1000 + round*50 + k)
+				}
+			}
+			for k := 2000; k < 3000; k++ {
+				readThrough(k)
+			}
+			hot := 0
+			for k := range 50 {
+				if c.Contains(k) {
+					hot++
+				}
+			}
+			if hot < 40 {
+				t.Errorf("\t %s should keep most of the hot set through a scan : %v of 50", test.name, hot)
+			}
+		}
+	}
+
+	t.Log("Given the need to test W-TinyLFU admission")
+	{
+		// a fixed hash, so collisions in the sketch are the same every run
+		policy := NewTinyLFUPolicy[int](10)
+		policy.sketch.hasher = fixedHash
+		c, _ := NewCache[int, int](policy)
+		for range 5 {
+			for k := range 10 {
+				c.Put(k, k)
+				c.Get(k)
+			}
+		}
+		for k := 100; k < 200; k++ {
+			c.Put(k, k) // each seen once, so not admitted over popular keys
+		}
+		kept := 0
+		for k := range 10 {
+			if c.Contains(k) {
+				kept++
+			}
+		}
+		if kept < 9 {
+			t.Errorf("\t W-TinyLFU should reject keys seen once : kept %v of 10", kept)
+		}
+	}
+}
+
+func TestCountMinSketch(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test the rows of the sketch are independent")
+	{
+		// keys sharing a counter in every row are counted as one, so
+		// should be no more common than chance over all four rows
+		const keys = 1000
+		s := newCountMinSketch[int](10)
+		s.hasher = fixedHash
+
+		indexes := make([][4]uint64, keys)
+		for k := range keys {
+			for row := range 4 {
+				indexes[k][row] = s.index(s.hasher(k), row)
+			}
+		}
+		shared := 0
+		for i := range keys {
+			for j := i + 1; j < keys; j++ {
+				if indexes[i] == indexes[j] {
+					shared++
+				}
+			}
+		}
+		if shared > 1 {
+			t.Errorf("\t %d pairs of keys share every counter", shared)
+		}
+	}
+}
+
+// Hash of an int which does not depend on the random seed of the default
+// hash, using FNV-1a over its bytes
+func fixedHash(k int) uint64 {
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, int64(k))
+	return h.Sum64()
+}
+
+// Synthetic key traces for comparing hit ratios
+func cacheTraces(size int) map[string][]int {
+	r := rand.New(rand.NewSource(1))
+	traces := map[string][]int{}
+
+	// popularity following a power law, as for web objects
+	zipf := rand.NewZipf(r, 1.1, 1, uint64(size*20))
+	trace := make([]int, size*20)
+	for i := range trace {
+		trace[i] = int(zipf.Uint64())
+	}
+	traces["zipf"] = trace
+
+	// a hot set interleaved with long scans of keys used once
+	trace = make([]int, 0, size*20)
+	for scan := 0; len(trace) < size*20; scan++ {
+		for range size * 2 {
+			trace = append(trace, r.Intn(size/2))
+		}
+		for k := range size {
+			trace = append(trace, size*(scan+1)+k)
+		}
+	}
+	traces["scan"] = trace
+
+	// a loop slightly larger than the cache
+	trace = make([]int, size*20)
+	for i := range trace {
+		trace[i] = i % (size + size/4)
+	}
+	traces["loop"] = trace
+
+	return traces
+}
+
+// Replays each trace against each policy, reporting the hit ratio.
+// A miss is followed by a Put, as a read-through cache would
+func BenchmarkCachePolicies(b *testing.B) {
+	const size = 1000
+
+	traces := cacheTraces(size)
+	names := []string{"zipf", "scan", "loop"}
+
+	for _, name := range names {
+		trace := traces[name]
+		for _, test := range cachePolicies {
+			b.Run(name+"/"+test.name, func(b *testing.B) {
+				var stats CacheStats
+				b.ReportAllocs()
+				for range b.N {
+					c, _ := NewCache[int, int](test.policy(size))
+					for _, k := range trace {
+						if _, ok := c.Get(k); !ok {
+							c.Put(k, k)
+						}
+					}
+					stats = c.Stats()
+				}
+				b.ReportMetric(100*stats.HitRatio(), "hit%")
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(trace)), "ns/access")
+			})
+		}
+	}
+}
@@ -0,0 +1,521 @@
+package godatastructures
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// default load factor, a limit to the ratio between size and capacity
+// a resize is started if the load factor is exceeded to prevent the
+// average list length growing too large, and a shrink if the ratio falls
+// below a quarter of it. Entries are then migrated a few buckets at a time
+const loadFactor float64 = 5
+
+// Upper limit on the number of lock stripes in a chained map
+const maxStripes = 64
+
+// Array of linked list buckets, migrated a bucket at a time when resizing
+type chainedTable[key comparable, val comparable] struct {
+	buckets   []List[MapEntry[key, val]]
+	evacuated []bool       // set once a bucket has been copied to the next table, guarded by its stripe
+	next      atomic.Int64 // the next bucket to evacuate
+	remaining atomic.Int64 // the number of buckets not yet evacuated
+}
+
+// constructor
+func newChainedTable[key comparable, val comparable](capacity int) *chainedTable[key, val] {
+	t := chainedTable[key, val]{
+		buckets: make([]List[MapEntry[key, val]], capacity),
+	}
+	return &t
+}
+
+// Map storage using an array of linked list buckets guarded by striped
+// locks. Bucket b is guarded by stripe b % len(stripes), and every path
+// holds the stripe lock across finding and modifying an entry.
+//
+// Capacities are kept to powers of two below maxStripes and multiples of
+// it above, so the stripe count divides the old and new capacities while
+// resizing, and a key is guarded by the same stripe in both arrays.
+// While resizing each write migrates the bucket of its key and one other
+// from the old array, so no operation waits for a full rehash. Reads of
+// a bucket not yet migrated use the old array
+type chainedMap[key comparable, val comparable] struct {
+	table       *chainedTable[key, val]
+	old         *chainedTable[key, val] // being migrated from, nil if not resizing
+	stripes     []sync.RWMutex
+	minCapacity int // the map does not shrink below its initial capacity
+	loadFactor  float64
+	growth      GrowthPolicy
+	resizeMode  ResizeMode
+	hasher      Hasher[key]
+	mutex       sync.RWMutex        // held exclusively only to swap table, old and stripes
+	resize      *semaphore.Weighted // Indicates resize in progress
+	size        atomic.Int64
+}
+
+// constructor
+func newChainedMap[key comparable, val comparable](config mapConfig, hasher Hasher[key]) *chainedMap[key, val] {
+
+	capacity := chainedCapacity(config.minCapacity)
+
+	m := chainedMap[key, val]{
+		table:       newChainedTable[key, val](capacity),
+		stripes:     make([]sync.RWMutex, stripesFor(capacity)),
+		minCapacity: capacity,
+		loadFactor:  config.loadFactor,
+		growth:      config.growth,
+		resizeMode:  config.resizeMode,
+		hasher:      hasher,
+		resize:      semaphore.NewWeighted(1),
+	}
+
+	return &m
+}
+
+// Returns the number of key-value mappings
+func (m *chainedMap[key, val]) len() int {
+	return int(m.size.Load())
+}
+
+// Returns the value mapped to the key
+// boolean ok indicates whether the value was present
+func (m *chainedMap[key, val]) get(k key) (value val, ok bool) {
+	h := m.hasher(k)
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stripe := m.stripe(h)
+	stripe.RLock()
+	defer stripe.RUnlock()
+
+	return entryValue(findNode(m.readBucket(h), k))
+}
+
+// Maps the value to the key, replacing any existing value
+func (m *chainedMap[key, val]) put(k key, v val) {
+	h := m.hasher(k)
+
+	m.mutex.RLock()
+	m.helpEvacuate()
+
+	stripe := m.stripe(h)
+	stripe.Lock()
+
+	bucket := m.writeBucket(h)
+	if node := findNode(bucket, k); node != nil {
+		node.value.value = v
+	} else {
+		bucket.AddFirst(MapEntry[key, val]{key: k, value: v})
+		m.size.Add(1)
+	}
+
+	stripe.Unlock()
+	m.mutex.RUnlock()
+
+	m.checkResize()
+}
+
+// Removes the mapping for the key if present
+func (m *chainedMap[key, val]) remove(k key) (ok bool) {
+	h := m.hasher(k)
+
+	m.mutex.RLock()
+	m.helpEvacuate()
+
+	stripe := m.stripe(h)
+	stripe.Lock()
+
+	bucket := m.writeBucket(h)
+	if node := findNode(bucket, k); node != nil {
+		bucket.Unlink(node)
+		m.size.Add(-1)
+		ok = true
+	}
+
+	stripe.Unlock()
+	m.mutex.RUnlock()
+
+	m.checkResize()
+	return
+}
+
+// Replaces the mapping for the key with the result of f, holding the
+// stripe write lock throughout. f receives the current value and whether
+// it was present, and returns the new value and whether to keep it
+func (m *chainedMap[key, val]) compute(k key, f func(old val, present bool) (v val, keep bool)) (value val, ok bool) {
+	h := m.hasher(k)
+
+	defer m.checkResize()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	m.helpEvacuate()
+
+	stripe := m.stripe(h)
+	stripe.Lock()
+	defer stripe.Unlock()
+
+	bucket := m.writeBucket(h)
+	node := findNode(bucket, k)
+	old, present := entryValue(node)
+
+	value, ok = f(old, present)
+
+	switch {
+	case ok && present:
+		node.value.value = value
+	case ok && !present:
+		bucket.AddFirst(MapEntry[key, val]{key: k, value: value})
+		m.size.Add(1)
+	case !ok && present:
+		bucket.Unlink(node)
+		m.size.Add(-1)
+	}
+	return
+}
+
+// Removes all of the mappings
+func (m *chainedMap[key, val]) clear() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	capacity := len(m.table.buckets)
+	m.table = newChainedTable[key, val](capacity)
+	if m.old != nil {
+		m.old = nil
+		m.stripes = make([]sync.RWMutex, stripesFor(capacity))
+		m.resize.Release(1)
+	}
+	m.size.Store(0)
+}
+
+// Calls yield with a copy of each entry, a bucket at a time.
+// Any migration in progress is completed first, so that only one
+// array needs visiting. Migration copies rather than moves entries,
+// so the array is unaffected if a later resize starts mid iteration
+func (m *chainedMap[key, val]) entries(yield func(MapEntry[key, val]) bool) {
+	m.mutex.RLock()
+	if old := m.old; old != nil {
+		for b := range old.buckets {
+			stripe := &m.stripes[b%len(m.stripes)]
+			stripe.Lock()
+			m.evacuate(old, b)
+			stripe.Unlock()
+		}
+	}
+	table := m.table
+	m.mutex.RUnlock()
+
+	m.checkResize()
+
+	var entries []MapEntry[key, val]
+	for b := range table.buckets {
+		entries = entries[:0]
+
+		// the stripe count always divides the capacity of the current and
+		// old arrays, and an array that is neither is no longer written
+		m.mutex.RLock()
+		stripe := &m.stripes[b%len(m.stripes)]
+		stripe.RLock()
+		for curr := table.buckets[b].first; curr != nil; curr = curr.next {
+			entries = append(entries, curr.value)
+		}
+		stripe.RUnlock()
+		m.mutex.RUnlock()
+
+		for _, e := range entries {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Returns a copy of every entry, with every stripe read locked
+func (m *chainedMap[key, val]) snapshot() []MapEntry[key, val] {
+	m.rlockAll()
+	defer m.runlockAll()
+
+	entries := make([]MapEntry[key, val], 0, m.size.Load())
+	for b := range m.table.buckets {
+		for curr := m.table.buckets[b].first; curr != nil; curr = curr.next {
+			entries = append(entries, curr.value)
+		}
+	}
+	// keys of buckets not yet migrated are only in the old array
+	if m.old != nil {
+		for b := range m.old.buckets {
+			if m.old.evacuated[b] {
+				continue
+			}
+			for curr := m.old.buckets[b].first; curr != nil; curr = curr.next {
+				entries = append(entries, curr.value)
+			}
+		}
+	}
+	return entries
+}
+
+// Reports whether each key is present, with every stripe read locked
+func (m *chainedMap[key, val]) containsEach(keys []key) []bool {
+	m.rlockAll()
+	defer m.runlockAll()
+
+	present := make([]bool, len(keys))
+	for i, k := range keys {
+		present[i] = findNode(m.readBucket(m.hasher(k)), k) != nil
+	}
+	return present
+}
+
+// Used internally to read lock the whole map. Stripes are taken in index
+// order, and every other path holds at most one stripe at a time,
+// so this cannot deadlock
+func (m *chainedMap[key, val]) rlockAll() {
+	m.mutex.RLock()
+	for i := range m.stripes {
+		m.stripes[i].RLock()
+	}
+}
+
+// Used internally to release the locks taken by rlockAll
+func (m *chainedMap[key, val]) runlockAll() {
+	for i := range m.stripes {
+		m.stripes[i].RUnlock()
+	}
+	m.mutex.RUnlock()
+}
+
+// Used internally to get the bucket to read a hash from, which is
+// in the old array if its bucket there is not yet migrated
+// Must be called with the stripe lock for the hash held
+func (m *chainedMap[key, val]) readBucket(h uint64) *List[MapEntry[key, val]] {
+	if m.old != nil {
+		if b := m.old.index(h); !m.old.evacuated[b] {
+			return &m.old.buckets[b]
+		}
+	}
+	return &m.table.buckets[m.table.index(h)]
+}
+
+// Used internally to get the stripe lock guarding a hash
+// Must be called with the read lock held
+func (m *chainedMap[key, val]) stripe(h uint64) *sync.RWMutex {
+	return &m.stripes[h%uint64(len(m.stripes))]
+}
+
+// Used internally to get the current bucket for a write, first migrating
+// the old bucket of the hash. Writes to a key always evacuate its old
+// bucket first, so an old bucket is never written to
+// Must be called with the stripe write lock for the hash held
+func (m *chainedMap[key, val]) writeBucket(h uint64) *List[MapEntry[key, val]] {
+	if m.old != nil {
+		m.evacuate(m.old, m.old.index(h))
+	}
+	return &m.table.buckets[m.table.index(h)]
+}
+
+// Used internally to migrate one more bucket of the old array before a write
+// Must be called with the read lock held, and no stripe locks
+func (m *chainedMap[key, val]) helpEvacuate() {
+	old := m.old
+	if old == nil {
+		return
+	}
+
+	if b := int(old.next.Add(1) - 1); b < len(old.buckets) {
+		stripe := &m.stripes[b%len(m.stripes)]
+		stripe.Lock()
+		m.evacuate(old, b)
+		stripe.Unlock()
+	}
+}
+
+// Used internally to copy a bucket of the old array into the current one.
+// The keys copied are never already present in the current array
+// Must be called with the stripe write lock for the bucket held
+func (m *chainedMap[key, val]) evacuate(old *chainedTable[key, val], b int) {
+	if old.evacuated[b] {
+		return
+	}
+
+	for curr := old.buckets[b].first; curr != nil; curr = curr.next {
+		e := curr.value
+		m.table.buckets[m.table.index(m.hasher(e.key))].AddFirst(e)
+	}
+
+	old.evacuated[b] = true
+	old.remaining.Add(-1)
+}
+
+// Used internally after a write to finish a completed migration,
+// or to start a resize if the load is outside the limits
+// Must be called without holding any locks
+func (m *chainedMap[key, val]) checkResize() {
+	m.mutex.RLock()
+	old := m.old
+	capacity := len(m.table.buckets)
+	m.mutex.RUnlock()
+
+	if old != nil {
+		if old.remaining.Load() == 0 {
+			m.finishResize(old)
+		}
+		return
+	}
+
+	size := float64(m.len())
+	limit := m.loadFactor * float64(capacity)
+
+	target := capacity
+	if size > limit {
+		target = chainedCapacity(max(capacity+1, m.growth(capacity)))
+	} else if capacity > m.minCapacity && size*4 < limit {
+		target = max(m.minCapacity, chainedCapacity(m.shrinkCapacity(size)))
+	}
+	if target == capacity {
+		return
+	}
+
+	old = m.startResize(target)
+	if old == nil {
+		return
+	}
+
+	switch m.resizeMode {
+	case BackgroundResize:
+		go m.migrate(old)
+	case SynchronousResize:
+		m.migrate(old)
+	}
+}
+
+// Used internally to swap in a new array for entries to migrate to
+// Returns the old array, or nil if a resize is already in progress
+func (m *chainedMap[key, val]) startResize(capacity int) *chainedTable[key, val] {
+	if !m.resize.TryAcquire(1) {
+		return nil // already resizing
+	}
+
+	table := newChainedTable[key, val](capacity)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	old := m.table
+	old.evacuated = make([]bool, len(old.buckets))
+	old.remaining.Store(int64(len(old.buckets)))
+
+	m.old = old
+	m.table = table
+	// no stripe locks are held while the map is locked exclusively
+	m.stripes = make([]sync.RWMutex, min(stripesFor(len(old.buckets)), stripesFor(capacity)))
+
+	return old
+}
+
+// Used internally to evacuate every bucket of the old array and finish
+// the resize, for the background and synchronous resize modes
+// Must be called without holding any locks
+func (m *chainedMap[key, val]) migrate(old *chainedTable[key, val]) {
+	for b := range old.buckets {
+		m.mutex.RLock()
+		if m.old != old { // cleared
+			m.mutex.RUnlock()
+			return
+		}
+		stripe := &m.stripes[b%len(m.stripes)]
+		stripe.Lock()
+		m.evacuate(old, b)
+		stripe.Unlock()
+		m.mutex.RUnlock()
+	}
+	m.finishResize(old)
+}
+
+// Used internally to release the old array once every bucket is migrated
+func (m *chainedMap[key, val]) finishResize(old *chainedTable[key, val]) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.old == old { // not already finished, or cleared
+		m.old = nil
+		m.stripes = make([]sync.RWMutex, stripesFor(len(m.table.buckets)))
+		m.resize.Release(1)
+	}
+}
+
+// Used internally to find the bucket index of a hash
+func (t *chainedTable[key, val]) index(h uint64) int {
+	return int(h % uint64(len(t.buckets)))
+}
+
+// Used internally to find the node for a key in a bucket
+// Must be called with the stripe lock held
+func findNode[key comparable, val comparable](bucket *List[MapEntry[key, val]], k key) *Node[MapEntry[key, val]] {
+	for curr := bucket.first; curr != nil; curr = curr.next {
+		if curr.value.key == k {
+			return curr
+		}
+	}
+	return nil
+}
+
+// Used internally to get the value from a node found by findNode
+// boolean ok indicates whether a node was found
+func entryValue[key comparable, val comparable](node *Node[MapEntry[key, val]]) (value val, ok bool) {
+	if node == nil {
+		return
+	}
+	return node.value.value, true
+}
+
+// Used internally to round a capacity to a power of two below
+// maxStripes, or to a multiple of maxStripes above it
+func chainedCapacity(capacity int) int {
+	if capacity >= maxStripes {
+		return (capacity + maxStripes - 1) / maxStripes * maxStripes
+	}
+	if capacity <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(capacity-1))
+}
+
+// Used internally to choose the number of stripes for a capacity
+// rounded by chainedCapacity, which the result always divides
+func stripesFor(capacity int) int {
+	return min(capacity, maxStripes)
+}
+
+// Used internally to decide (smoothed) growth rate. Figures based on
+// https://go.googlesource.com/go/+/2dda92ff6f9f07eeb110ecbf0fc2d7a0ddd27f9d
+func newCapacity(currentCapacity int) int {
+
+	if currentCapacity < 256 {
+		return 2 * currentCapacity
+	}
+	if currentCapacity < 512 {
+		return int(1.63 * float64(currentCapacity))
+	}
+	if currentCapacity < 1024 {
+		return int(1.44 * float64(currentCapacity))
+	}
+	if currentCapacity < 2048 {
+		return int(1.35 * float64(currentCapacity))
+	}
+	return int(1.30 * float64(currentCapacity))
+}
+
+// Used internally to decide the capacity to shrink to, leaving
+// the average list length at half the load factor
+func (m *chainedMap[key, val]) shrinkCapacity(size float64) int {
+	return max(1, int(math.Ceil(2*size/m.loadFactor)))
+}
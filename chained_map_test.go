@@ -0,0 +1,266 @@
+package godatastructures
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChainedMapResize(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test incremental resizing of the chained map")
+	{
+		numItems := 5000
+
+		m := NewMap[int, int](2)
+		chained := m.store.(*chainedMap[int, int])
+
+		t.Logf("Testing growth while putting %v items", numItems)
+
+		migrations := 0
+		for i := range numItems {
+			m.Put(i, i)
+
+			chained.mutex.RLock()
+			migrating := chained.old != nil
+			chained.mutex.RUnlock()
+
+			if migrating {
+				migrations++
+				// every key must be found whether or not its bucket has migrated
+				for n := 0; n <= i; n += 97 {
+					if v, ok := m.Get(n); !ok || v != n {
+						t.Fatalf("\t Get during migration expected %v : %v", n, v)
+					}
+				}
+			}
+		}
+
+		if migrations == 0 {
+			t.Errorf("\t Expected puts to be made while migrating")
+		}
+		if m.Size() != numItems {
+			t.Errorf("\t Size after growth expected %v : %v", numItems, m.Size())
+		}
+
+		grownCapacity := len(chained.table.buckets)
+		if grownCapacity <= 2 {
+			t.Errorf("\t Capacity should have grown : %v", grownCapacity)
+		}
+
+		t.Log("Testing shrinking after removing most items")
+
+		for i := range numItems - 10 {
+			if !m.Remove(i) {
+				t.Fatalf("\t Remove of %v expected to succeed", i)
+			}
+		}
+		m.Entries()(func(MapEntry[int, int]) bool { return true }) // complete any migration
+
+		shrunkCapacity := len(chained.table.buckets)
+		if shrunkCapacity >= grownCapacity {
+			t.Errorf("\t Capacity should have shrunk %v : %v", grownCapacity, shrunkCapacity)
+		}
+		if shrunkCapacity < chained.minCapacity {
+			t.Errorf("\t Capacity should not shrink below the initial capacity %v : %v", chained.minCapacity, shrunkCapacity)
+		}
+
+		for i := numItems - 10; i < numItems; i++ {
+			if v, ok := m.Get(i); !ok || v != i {
+				t.Errorf("\t Get after shrinking expected %v : %v", i, v)
+			}
+		}
+	}
+}
+
+func TestChainedMapResizeConcurrent(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test resizing with concurrent readers and writers")
+	{
+		concurrency := 8
+		perRoutine := 2000
+
+		m := NewMap[string, int](1)
+
+		wg := sync.WaitGroup{}
+		wg.Add(concurrency)
+		for g := range concurrency {
+			go func() {
+				defer wg.Done()
+				for i := range perRoutine {
+					k := strconv.Itoa(g*perRoutine + i)
+					m.Put(k, i)
+					if v, ok := m.Get(k); !ok || v != i {
+						t.Errorf("\t Get after Put expected %v : %v", i, v)
+					}
+					if i%2 == 0 {
+						m.Remove(k)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		if m.Size() != concurrency*perRoutine/2 {
+			t.Errorf("\t Size expected %v : %v", concurrency*perRoutine/2, m.Size())
+		}
+
+		count := 0
+		for range m.Keys() {
+			count++
+		}
+		if count != m.Size() {
+			t.Errorf("\t Keys expected %v : %v", m.Size(), count)
+		}
+	}
+}
+
+// Report the worst Put latency seen while a map grows from empty,
+// which stays flat as no single Put performs a full rehash
+func BenchmarkChainedMapGrowth(b *testing.B) {
+	for range b.N {
+		m := NewMap[int, int](1)
+		var worst time.Duration
+		for i := range 1 << 17 {
+			start := time.Now()
+			m.Put(i, i)
+			worst = max(worst, time.Since(start))
+		}
+		b.ReportMetric(float64(worst.Microseconds()), "max-put-µs")
+	}
+}
+
+func TestChainedMapResizeOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		resizeMode ResizeMode
+	}{
+		{name: "incremental", resizeMode: IncrementalResize},
+		{name: "background", resizeMode: BackgroundResize},
+		{name: "synchronous", resizeMode: SynchronousResize},
+	}
+
+	t.Log("Given the need to test chained map resize settings")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When resizing with %v migration", i, test.name)
+			{
+				m, err := NewMapWithOptions[int, int](
+					WithMinCapacity(4),
+					WithLoadFactor(1),
+					WithGrowthPolicy(func(c int) int { return c * 4 }),
+					WithResizeMode(test.resizeMode),
+				)
+				if err != nil {
+					t.Fatalf("\t%d\t Unexpected error %v", i, err)
+				}
+				chained := m.store.(*chainedMap[int, int])
+
+				for n := range 5 { // exceeds a load factor of 1 on 4 buckets
+					m.Put(n, n)
+				}
+
+				chained.mutex.RLock()
+				capacity := len(chained.table.buckets)
+				migrating := chained.old != nil
+				chained.mutex.RUnlock()
+
+				if capacity != 16 {
+					t.Errorf("\t%d\t Growth policy should give capacity 16 : %v", i, capacity)
+				}
+				if test.resizeMode == SynchronousResize && migrating {
+					t.Errorf("\t%d\t Synchronous resize should complete before Put returns", i)
+				}
+
+				if test.resizeMode == BackgroundResize {
+					deadline := time.Now().Add(5 * time.Second)
+					for migrating && time.Now().Before(deadline) {
+						time.Sleep(time.Millisecond)
+						chained.mutex.RLock()
+						migrating = chained.old != nil
+						chained.mutex.RUnlock()
+					}
+					if migrating {
+						t.Errorf("\t%d\t Background resize did not complete", i)
+					}
+				}
+
+				for n := range 5 {
+					if v, ok := m.Get(n); !ok || v != n {
+						t.Errorf("\t%d\t Get after resize expected %v : %v", i, n, v)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestChainedCapacity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		capacity int
+		expected int
+	}{
+		{capacity: 0, expected: 1},
+		{capacity: 1, expected: 1},
+		{capacity: 3, expected: 4},
+		{capacity: 33, expected: 64},
+		{capacity: 64, expected: 64},
+		{capacity: 65, expected: 128},
+		{capacity: 200, expected: 256},
+		{capacity: 1000, expected: 1024},
+		{capacity: 1030, expected: 1088},
+	}
+
+	t.Log("Given the need to keep stripe counts dividing chained map capacities")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When rounding capacity %v", i, test.capacity)
+
+			capacity := chainedCapacity(test.capacity)
+			if capacity != test.expected {
+				t.Errorf("\t%d\t Expected capacity %v : %v", i, test.expected, capacity)
+			}
+			for _, other := range tests {
+				stripes := min(stripesFor(capacity), stripesFor(chainedCapacity(other.capacity)))
+				if capacity%stripes != 0 {
+					t.Errorf("\t%d\t Stripes %v do not divide capacity %v", i, stripes, capacity)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkChainedMapParallel(b *testing.B) {
+	const size = 1 << 12
+
+	m := NewMap[int, int](size)
+	for i := range size {
+		m.Put(i, i)
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				m.Get(i % size)
+				i++
+			}
+		})
+	})
+	b.Run("Put", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				m.Put(i%size, i)
+				i++
+			}
+		})
+	})
+}
@@ -0,0 +1,49 @@
+package godatastructures
+
+// Heap in which each element has arity children rather than two.
+// A wider heap is shallower, so Put moves values up fewer levels,
+// and the children compared by Get sit together in memory, which
+// suits large queues. It has every operation of Heap, with handles
+type DaryHeap[val any] struct {
+	Heap[val]
+}
+
+var _ PriorityQueue[int, *HeapHandle[int]] = (*DaryHeap[int])(nil)
+
+// constructor, with the backing slice sized for capacity values
+// An arity below 2 is treated as 2
+func NewDaryHeap[val any](arity int, capacity int, f func(val, val) int) *DaryHeap[val] {
+	d := DaryHeap[val]{
+		Heap: Heap[val]{
+			slice:   make([]*HeapHandle[val], 0, max(capacity, 0)),
+			compare: f,
+			arity:   max(arity, 2),
+		},
+	}
+	return &d
+}
+
+// constructor, building the heap from the values in O(n)
+func NewDaryHeapFromSlice[val any](arity int, values []val, f func(val, val) int) *DaryHeap[val] {
+	d := NewDaryHeap(arity, len(values), f)
+	d.appendValues(values)
+	d.heapify()
+
+	return d
+}
+
+// Returns the arity of the heap
+func (d *DaryHeap[val]) Arity() int {
+	return d.arity
+}
+
+// Returns a copy of the heap with the same arity, values and compare
+// function. Handles of this heap are not valid for the copy
+func (d *DaryHeap[val]) Clone() *DaryHeap[val] {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	c := NewDaryHeap(d.arity, len(d.slice), d.compare)
+	d.copyTo(&c.Heap)
+	return c
+}
@@ -0,0 +1,63 @@
+package godatastructures
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDaryHeap(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test DaryHeap ordering for several arities")
+	{
+		data := rand.New(rand.NewSource(1)).Perm(200)
+		sorted := slices.Clone(data)
+		slices.Sort(sorted)
+
+		for _, arity := range []int{0, 2, 3, 4, 8} {
+			t.Logf("\tTest: %d\t When testing arity %d", arity, arity)
+			{
+				d := NewDaryHeap(arity, len(data), SortAscendingInt)
+				if d.Arity() != max(arity, 2) {
+					t.Errorf("\t%d\t Arity expected %v : %v", arity, max(arity, 2), d.Arity())
+				}
+				handles := make([]*HeapHandle[int], len(data))
+				for i, n := range data {
+					handles[i] = d.Put(n)
+				}
+
+				c := d.Clone()
+				if values := slices.Collect(c.All()); !slices.Equal(values, sorted) {
+					t.Errorf("\t%d\t Clone should yield values in heap order : %v", arity, values)
+				}
+				if c.Arity() != d.Arity() {
+					t.Errorf("\t%d\t Clone should keep the arity %v : %v", arity, d.Arity(), c.Arity())
+				}
+
+				// move every odd value to the front, and remove every tenth
+				expected := []int{}
+				for i, n := range data {
+					switch {
+					case n%10 == 0:
+						d.Remove(handles[i])
+					case n%2 == 1:
+						d.Update(handles[i], -n)
+						expected = append(expected, -n)
+					default:
+						expected = append(expected, n)
+					}
+				}
+				slices.Sort(expected)
+				if values := d.Drain(); !slices.Equal(values, expected) {
+					t.Errorf("\t%d\t Drain after updates expected %v : %v", arity, expected, values)
+				}
+
+				f := NewDaryHeapFromSlice(arity, data, SortAscendingInt)
+				if values := f.Drain(); !slices.Equal(values, sorted) {
+					t.Errorf("\t%d\t NewDaryHeapFromSlice should drain in heap order : %v", arity, values)
+				}
+			}
+		}
+	}
+}
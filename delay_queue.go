@@ -0,0 +1,128 @@
+package godatastructures
+
+import (
+	"context"
+	"time"
+)
+
+// Source of the current time and timers for a DelayQueue,
+// replaceable so tests can advance time deterministically
+type Clock interface {
+	// Returns the current time
+	Now() time.Time
+	// Returns a channel which receives once the duration has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// Clock using the system time
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Value waiting in a DelayQueue until its deadline
+type delayed[val any] struct {
+	value    val
+	deadline time.Time
+}
+
+// Queue whose values can only be taken once their deadline has passed,
+// earliest deadline first, held in a Heap ordered by deadline.
+// Waiting takers sleep until the deadline of the earliest value, and are
+// woken early by closing the changed channel when a value is put with
+// an earlier deadline. It is guarded by the mutex of the heap
+type DelayQueue[val any] struct {
+	heap    *Heap[delayed[val]]
+	clock   Clock
+	changed chan struct{} // closed when the earliest deadline changes
+}
+
+// constructor, using the system clock
+func NewDelayQueue[val any]() *DelayQueue[val] {
+	return NewDelayQueueWithClock[val](systemClock{})
+}
+
+// constructor, using the supplied clock
+func NewDelayQueueWithClock[val any](clock Clock) *DelayQueue[val] {
+	q := DelayQueue[val]{
+		heap: NewHeap(0, func(a, b delayed[val]) int {
+			return a.deadline.Compare(b.deadline)
+		}),
+		clock:   clock,
+		changed: make(chan struct{}),
+	}
+	return &q
+}
+
+// Gets the number of values in the queue, whether or not they are due
+func (q *DelayQueue[val]) Size() int {
+	return q.heap.Size()
+}
+
+// Puts the value on the queue, to be taken once the deadline has passed
+func (q *DelayQueue[val]) Put(value val, deadline time.Time) {
+	q.heap.mutex.Lock()
+	defer q.heap.mutex.Unlock()
+
+	if q.heap.put(delayed[val]{value: value, deadline: deadline}).index == 0 {
+		q.signal()
+	}
+}
+
+// Puts the value on the queue, to be taken once the delay has elapsed
+func (q *DelayQueue[val]) PutAfter(value val, delay time.Duration) {
+	q.Put(value, q.clock.Now().Add(delay))
+}
+
+// Returns the earliest deadline in the queue without removing its value
+// boolean ok indicates whether the queue held a value
+func (q *DelayQueue[val]) PeekDeadline() (deadline time.Time, ok bool) {
+	head, ok := q.heap.Peek()
+	return head.deadline, ok
+}
+
+// Get and remove the value with the earliest deadline, waiting until
+// its deadline has passed. Returns the context's error if it is done first
+func (q *DelayQueue[val]) Take(ctx context.Context) (value val, err error) {
+	for {
+		q.heap.mutex.Lock()
+		var timer <-chan time.Time // nil, waiting forever, while empty
+		if len(q.heap.slice) > 0 {
+			wait := q.heap.slice[0].value.deadline.Sub(q.clock.Now())
+			if wait <= 0 {
+				value = q.heap.removeAt(0).value
+				q.heap.mutex.Unlock()
+				return value, nil
+			}
+			timer = q.clock.After(wait)
+		}
+		changed := q.changed
+		q.heap.mutex.Unlock()
+
+		select {
+		case <-timer:
+		case <-changed:
+		case <-ctx.Done():
+			return value, ctx.Err()
+		}
+	}
+}
+
+// Get and remove the value with the earliest deadline without waiting
+// boolean ok indicates whether a value was due
+func (q *DelayQueue[val]) TryTake() (value val, ok bool) {
+	q.heap.mutex.Lock()
+	defer q.heap.mutex.Unlock()
+
+	if len(q.heap.slice) == 0 || q.heap.slice[0].value.deadline.After(q.clock.Now()) {
+		return
+	}
+	return q.heap.removeAt(0).value, true
+}
+
+// Used internally to wake every waiter to check the earliest deadline
+// Must be called with the write lock held
+func (q *DelayQueue[val]) signal() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
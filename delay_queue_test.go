@@ -0,0 +1,153 @@
+package godatastructures
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Clock which only moves when advanced, for deterministic tests
+type fakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	waiting chan struct{} // receives each time a timer is created
+}
+
+type fakeTimer struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		waiting: make(chan struct{}, 100),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timer := fakeTimer{deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, timer)
+	c.waiting <- struct{}{}
+	return timer.c
+}
+
+// Moves the clock forward, firing every timer that has become due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+}
+
+func TestDelayQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test DelayQueue without waiting")
+	{
+		clock := newFakeClock()
+		q := NewDelayQueueWithClock[string](clock)
+
+		q.PutAfter("b", 2*time.Second)
+		q.PutAfter("c", 3*time.Second)
+		q.Put("a", clock.Now().Add(time.Second))
+
+		if deadline, ok := q.PeekDeadline(); !ok || !deadline.Equal(clock.Now().Add(time.Second)) {
+			t.Errorf("\t PeekDeadline expected the earliest deadline : %v %v", deadline, ok)
+		}
+		if v, ok := q.TryTake(); ok {
+			t.Errorf("\t TryTake before any deadline returned a value: %v", v)
+		}
+
+		clock.Advance(2 * time.Second)
+		for _, want := range []string{"a", "b"} {
+			if v, ok := q.TryTake(); !ok || v != want {
+				t.Errorf("\t TryTake expected %v : %v %v", want, v, ok)
+			}
+		}
+		if v, ok := q.TryTake(); ok {
+			t.Errorf("\t TryTake before the last deadline returned a value: %v", v)
+		}
+		if q.Size() != 1 {
+			t.Errorf("\t Size expected 1 : %v", q.Size())
+		}
+	}
+
+	t.Log("Given the need to test DelayQueue Take waits for the deadline")
+	{
+		clock := newFakeClock()
+		q := NewDelayQueueWithClock[string](clock)
+		q.PutAfter("late", 10*time.Second)
+
+		taken := make(chan string)
+		go func() {
+			v, err := q.Take(context.Background())
+			if err != nil {
+				t.Errorf("\t Take failed : %v", err)
+			}
+			taken <- v
+		}()
+
+		<-clock.waiting // Take is waiting for the 10 second deadline
+		q.PutAfter("early", 5*time.Second)
+		<-clock.waiting // woken by the earlier deadline, now waiting for it
+
+		clock.Advance(4 * time.Second)
+		select {
+		case v := <-taken:
+			t.Errorf("\t Take returned %v before its deadline", v)
+		default:
+		}
+
+		clock.Advance(time.Second)
+		if v := <-taken; v != "early" {
+			t.Errorf("\t Take expected early : %v", v)
+		}
+	}
+
+	t.Log("Given the need to test DelayQueue cancellation")
+	{
+		clock := newFakeClock()
+		q := NewDelayQueueWithClock[int](clock)
+		q.PutAfter(1, time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := q.Take(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("\t Take expected cancelled : %v", err)
+		}
+		if q.Size() != 1 {
+			t.Errorf("\t Take should not remove after cancellation : %v", q.Size())
+		}
+	}
+
+	t.Log("Given the need to test DelayQueue with the system clock")
+	{
+		q := NewDelayQueue[int]()
+		q.PutAfter(1, time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if v, err := q.Take(ctx); err != nil || v != 1 {
+			t.Errorf("\t Take expected 1 : %v %v", v, err)
+		}
+	}
+}
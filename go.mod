@@ -1,6 +1,6 @@
 module github.com/stephenirven/go-datastructures
 
-go 1.22.1
+go 1.24
 
 require github.com/google/go-cmp v0.6.0
 
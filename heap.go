@@ -1,27 +1,71 @@
 package godatastructures
 
 import (
+	"iter"
+	"slices"
 	"sync"
 )
 
+// Operations shared by the priority queue implementations, with
+// values ordered by a compare function and taken smallest first.
+// Put returns a handle of the implementation's type, which can then
+// be used to update or remove the value wherever it has moved to
+type PriorityQueue[val any, handle any] interface {
+	// Puts the value on the queue, returning a handle to it
+	Put(value val) handle
+	// Replaces the value of the handle, returning false if it was removed
+	Update(h handle, value val) bool
+	// Removes the value of the handle, ok is false if it was removed
+	Remove(h handle) (value val, ok bool)
+	// Gets and removes the value from the top of the queue
+	Get() (value val, ok bool)
+	// Gets the value from the top of the queue without removing it
+	Peek() (value val, ok bool)
+	// Gets the number of values on the queue
+	Size() int
+	// Returns an iterator over the values in order, without removing them
+	All() iter.Seq[val]
+}
+
 // Generic Heap structure using supplied compare function
 type Heap[val any] struct {
-	slice   []val
+	slice   []*HeapHandle[val]
 	compare func(v1, v2 val) int
+	arity   int // the number of children of each element, 2 unless a DaryHeap
 	mutex   sync.RWMutex
 }
 
-// constructor
+var _ PriorityQueue[int, *HeapHandle[int]] = (*Heap[int])(nil)
+
+// Opaque handle to a value on the heap, returned by Put, used to
+// update or remove the value wherever it has moved to
+type HeapHandle[val any] struct {
+	value val
+	index int        // position in the heap slice, -1 once removed
+	heap  *Heap[val] // the heap holding the value
+}
+
+// constructor, with the backing slice sized for capacity values
 func NewHeap[val any](capacity int, f func(val, val) int) *Heap[val] {
 	h := Heap[val]{
-		slice:   make([]val, 0),
+		slice:   make([]*HeapHandle[val], 0, max(capacity, 0)),
 		compare: f,
+		arity:   2,
 		mutex:   sync.RWMutex{},
 	}
 
 	return &h
 }
 
+// constructor, building the heap from the values in O(n)
+func NewHeapFromSlice[val any](values []val, f func(val, val) int) *Heap[val] {
+	h := NewHeap(len(values), f)
+	h.appendValues(values)
+	h.heapify()
+
+	return h
+}
+
 // Get the item at the top of the heap without removing it
 func (h *Heap[val]) Peek() (value val, ok bool) {
 	h.mutex.RLock()
@@ -31,7 +75,7 @@ func (h *Heap[val]) Peek() (value val, ok bool) {
 		return
 	}
 	ok = true
-	value = h.slice[0]
+	value = h.slice[0].value
 	return
 }
 
@@ -44,21 +88,104 @@ func (h *Heap[val]) Get() (value val, ok bool) {
 		return
 	}
 	ok = true
-	value = h.slice[0]
-	h.slice[0], h.slice[len(h.slice)-1] = h.slice[len(h.slice)-1], h.slice[0]
-	h.slice = h.slice[:len(h.slice)-1]
-	h.bubbleDown()
+	value = h.removeAt(0)
 
 	return
 }
 
-// Puts the value on the heap
-func (h *Heap[val]) Put(value val) {
+// Puts the value on the heap, returning a handle to it
+func (h *Heap[val]) Put(value val) *HeapHandle[val] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.put(value)
+}
+
+// Puts all the values on the heap. A large batch is added by
+// rebuilding the heap in O(n), a small one value by value
+func (h *Heap[val]) PutAll(values []val) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.putAll(values)
+}
+
+// Puts the values of the other heap on this heap, leaving the other
+// heap unchanged. The other heap is copied under its own read lock,
+// so the locks of both heaps are never held together
+func (h *Heap[val]) Merge(other *Heap[val]) {
+	other.mutex.RLock()
+	values := make([]val, len(other.slice))
+	for i, handle := range other.slice {
+		values[i] = handle.value
+	}
+	other.mutex.RUnlock()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.putAll(values)
+}
+
+// Removes every value from the heap, returning them in heap order
+func (h *Heap[val]) Drain() []val {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	values := make([]val, 0, len(h.slice))
+	for len(h.slice) > 0 {
+		values = append(values, h.removeAt(0))
+	}
+	return values
+}
+
+// Returns a copy of the heap with the same values and compare function.
+// Handles of this heap are not valid for the copy
+func (h *Heap[val]) Clone() *Heap[val] {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.clone()
+}
+
+// Replaces the value of the handle and moves it to its new heap location
+// in O(log n). Returns false if the handle is no longer on the heap
+func (h *Heap[val]) Update(handle *HeapHandle[val], value val) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.holds(handle) {
+		return false
+	}
+	handle.value = value
+	h.fix(handle.index)
+	return true
+}
+
+// Removes the value of the handle from the heap in O(log n)
+// boolean ok indicates whether the handle was still on the heap
+func (h *Heap[val]) Remove(handle *HeapHandle[val]) (value val, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.holds(handle) {
+		return
+	}
+	return h.removeAt(handle.index), true
+}
+
+// Moves the value of the handle to its correct heap location in O(log n),
+// after its ordering has been changed in place, such as through a pointer
+// Returns false if the handle is no longer on the heap
+func (h *Heap[val]) Fix(handle *HeapHandle[val]) bool {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
-	h.slice = append(h.slice, value)
-	h.bubbleUp()
+	if !h.holds(handle) {
+		return false
+	}
+	h.fix(handle.index)
+	return true
 }
 
 // Gets the size of the heap
@@ -69,33 +196,158 @@ func (h *Heap[val]) Size() int {
 	return len(h.slice)
 }
 
-// Moves the element from last position into correct heap location
-func (h *Heap[val]) bubbleUp() {
+// Returns an iterator over the values of the heap in sorted order,
+// without removing them. The heap is copied under the read lock when
+// iteration starts, so the loop body may safely modify the heap, and
+// each value is found lazily in O(log n)
+func (h *Heap[val]) All() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		h.mutex.RLock()
+		c := h.clone()
+		h.mutex.RUnlock()
+
+		for v, ok := c.Get(); ok; v, ok = c.Get() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Used internally to copy the heap, which is already in heap order
+// Must be called with the lock held
+func (h *Heap[val]) clone() *Heap[val] {
+	c := NewHeap(len(h.slice), h.compare)
+	c.arity = h.arity
+	h.copyTo(c)
+	return c
+}
+
+// Used internally to copy the values into an empty heap of the same arity
+// The copy needs its own handles, so its moves do not change the
+// positions recorded for the original
+// Must be called with the lock held
+func (h *Heap[val]) copyTo(c *Heap[val]) {
+	for i, handle := range h.slice {
+		c.slice = append(c.slice, &HeapHandle[val]{value: handle.value, index: i, heap: c})
+	}
+}
+
+// Used internally to add a value and move it to its heap location
+// Must be called with the write lock held
+func (h *Heap[val]) put(value val) *HeapHandle[val] {
+	handle := &HeapHandle[val]{value: value, index: len(h.slice), heap: h}
+	h.slice = append(h.slice, handle)
+	h.bubbleUp(handle.index)
+
+	return handle
+}
 
-	idx := len(h.slice) - 1
+// Used internally to add values, rebuilding the heap when the batch
+// is at least half the size of the heap, as bubbling each value up
+// would then cost more than the O(n) rebuild
+// Must be called with the write lock held
+func (h *Heap[val]) putAll(values []val) {
+	if len(values) < len(h.slice)/2 {
+		for _, v := range values {
+			h.put(v)
+		}
+		return
+	}
+	h.appendValues(values)
+	h.heapify()
+}
 
-	parentIdx, ok := parent(idx)
+// Used internally to append values with new handles, without
+// moving them to their heap locations
+// Must be called with the write lock held
+func (h *Heap[val]) appendValues(values []val) {
+	h.slice = slices.Grow(h.slice, len(values))
+	for _, v := range values {
+		h.slice = append(h.slice, &HeapHandle[val]{value: v, index: len(h.slice), heap: h})
+	}
+}
+
+// Used internally to restore heap order over the whole slice in O(n)
+// using Floyd's method, bubbling down each parent from the last
+// Must be called with the write lock held
+func (h *Heap[val]) heapify() {
+	last, ok := h.parent(len(h.slice) - 1)
+	if !ok {
+		return
+	}
+	for idx := last; idx >= 0; idx-- {
+		h.bubbleDown(idx)
+	}
+}
+
+// Used internally to check the handle is on this heap
+// Must be called with the lock held
+func (h *Heap[val]) holds(handle *HeapHandle[val]) bool {
+	return handle != nil && handle.heap == h && handle.index >= 0
+}
+
+// Used internally to remove the value at the index, filling its place
+// with the last value and moving that to its correct heap location
+// Must be called with the write lock held
+func (h *Heap[val]) removeAt(idx int) val {
+	last := len(h.slice) - 1
+	h.swap(idx, last)
+
+	handle := h.slice[last]
+	h.slice[last] = nil
+	h.slice = h.slice[:last]
+	handle.index = -1
+
+	if idx < last {
+		h.fix(idx)
+	}
+	return handle.value
+}
+
+// Used internally to move the value at the index up or down
+// to its correct heap location
+// Must be called with the write lock held
+func (h *Heap[val]) fix(idx int) {
+	if !h.bubbleUp(idx) {
+		h.bubbleDown(idx)
+	}
+}
+
+// Used internally to swap two values, keeping their handles' indexes current
+func (h *Heap[val]) swap(i, j int) {
+	h.slice[i], h.slice[j] = h.slice[j], h.slice[i]
+	h.slice[i].index = i
+	h.slice[j].index = j
+}
+
+// Moves the element at the index up into correct heap location
+// Returns whether the element moved
+func (h *Heap[val]) bubbleUp(idx int) (moved bool) {
+
+	parentIdx, ok := h.parent(idx)
 
 	for ok {
-		if h.compare(h.slice[parentIdx], h.slice[idx]) > 0 {
-			h.slice[parentIdx], h.slice[idx] = h.slice[idx], h.slice[parentIdx]
+		if h.compare(h.slice[parentIdx].value, h.slice[idx].value) > 0 {
+			h.swap(parentIdx, idx)
+			moved = true
 			idx = parentIdx
-			parentIdx, ok = parent(idx)
+			parentIdx, ok = h.parent(idx)
 		} else {
 			return
 		}
 	}
+	return
 }
 
-// Moves the element from first position into correct heap location
-func (h *Heap[val]) bubbleDown() {
-	idx := 0
+// Moves the element at the index down into correct heap location
+func (h *Heap[val]) bubbleDown(idx int) {
 
 	childIdx, ok := h.bestChild(idx)
 
 	for ok {
-		if h.compare(h.slice[idx], h.slice[childIdx]) > 0 {
-			h.slice[idx], h.slice[childIdx] = h.slice[childIdx], h.slice[idx]
+		if h.compare(h.slice[idx].value, h.slice[childIdx].value) > 0 {
+			h.swap(idx, childIdx)
 			idx = childIdx
 			childIdx, ok = h.bestChild(idx)
 		} else {
@@ -106,36 +358,30 @@ func (h *Heap[val]) bubbleDown() {
 }
 
 // Get the parent index of the supplied index
-func parent(idx int) (parentIdx int, ok bool) {
-	if idx == 0 {
+func (h *Heap[val]) parent(idx int) (parentIdx int, ok bool) {
+	if idx <= 0 {
 		ok = false
 		return
 	}
 	ok = true
-	parentIdx = (idx - 1) / 2
+	parentIdx = (idx - 1) / h.arity
 	return
 }
 
 // finds the child index most likely to bubble up
 func (h *Heap[val]) bestChild(index int) (child int, ok bool) {
-	child1 := (2 * index) + 1
-	child2 := child1 + 1
-
-	if child2 >= len(h.slice) {
-		if child1 >= len(h.slice) {
-			ok = false
-			return
-		}
-		ok = true
-		child = child1
+	first := (h.arity * index) + 1
+	if first >= len(h.slice) {
+		ok = false
 		return
 	}
 
 	ok = true
-	if h.compare(h.slice[child1], h.slice[child2]) > 0 {
-		child = child2
-		return
+	child = first
+	for c := first + 1; c < min(first+h.arity, len(h.slice)); c++ {
+		if h.compare(h.slice[child].value, h.slice[c].value) > 0 {
+			child = c
+		}
 	}
-	child = child1
 	return
 }
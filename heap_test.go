@@ -2,7 +2,9 @@ package godatastructures
 
 import (
 	"cmp"
+	"math/rand"
 	"slices"
+	"strconv"
 	"testing"
 )
 
@@ -179,3 +181,282 @@ func TestHeap(t *testing.T) {
 		}
 	}
 }
+
+func TestHeapIterator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		data        []int
+		compareFunc func(i, j int) int
+	}{
+		{data: []int{2, 1, 4, 3, 5}, compareFunc: SortAscendingInt},
+		{data: []int{2, 1, 4, 4, 5}, compareFunc: SortDescendingInt},
+		{data: []int{}, compareFunc: SortAscendingInt},
+	}
+
+	t.Log("Given the need to test Heap iteration")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing source data %v ", i, test.data)
+			{
+				h := NewHeap(10, test.compareFunc)
+				for _, n := range test.data {
+					h.Put(n)
+				}
+
+				sorted := slices.Clone(test.data)
+				slices.SortFunc(sorted, test.compareFunc)
+
+				values := slices.Collect(h.All())
+				if !slices.Equal(values, sorted) {
+					t.Errorf("\t%d\t All should yield values in heap order %v : %v", i, sorted, values)
+				}
+
+				if h.Size() != len(test.data) {
+					t.Errorf("\t%d\t All should not consume the heap %v : %v", i, len(test.data), h.Size())
+				}
+
+				for v := range h.All() {
+					h.Put(v) // modifying during iteration must not deadlock
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestHeapHandles(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test Heap Update, Remove and Fix by handle")
+	{
+		h := NewHeap(10, SortAscendingInt)
+		handles := make(map[int]*HeapHandle[int])
+		for _, n := range []int{50, 20, 80, 10, 60, 30, 70, 40} {
+			handles[n] = h.Put(n)
+		}
+
+		t.Log("\t\t Testing Update decreases and increases keys")
+		if !h.Update(handles[80], 5) {
+			t.Errorf("\t Update on a live handle should succeed")
+		}
+		if v, _ := h.Peek(); v != 5 {
+			t.Errorf("\t Peek after decrease-key expected 5 : %v", v)
+		}
+		if !h.Update(handles[10], 90) {
+			t.Errorf("\t Update on a live handle should succeed")
+		}
+
+		t.Log("\t\t Testing Remove from the middle of the heap")
+		if v, ok := h.Remove(handles[40]); !ok || v != 40 {
+			t.Errorf("\t Remove expected 40 : %v %v", v, ok)
+		}
+		if _, ok := h.Remove(handles[40]); ok {
+			t.Errorf("\t Remove on a removed handle should fail")
+		}
+		if h.Update(handles[40], 1) || h.Fix(handles[40]) {
+			t.Errorf("\t Update and Fix on a removed handle should fail")
+		}
+
+		other := NewHeap(10, SortAscendingInt)
+		if other.Fix(handles[20]) {
+			t.Errorf("\t Fix with another heap's handle should fail")
+		}
+
+		expected := []int{5, 20, 30, 50, 60, 70, 90}
+		if values := slices.Collect(h.All()); !slices.Equal(values, expected) {
+			t.Errorf("\t All after updates expected %v : %v", expected, values)
+		}
+
+		t.Log("\t\t Testing handles track values as they move")
+		for _, n := range []int{20, 30, 50} {
+			if v, ok := h.Remove(handles[n]); !ok || v != n {
+				t.Errorf("\t Remove expected %v : %v %v", n, v, ok)
+			}
+		}
+		for _, want := range []int{5, 60, 70, 90} {
+			if v, ok := h.Get(); !ok || v != want {
+				t.Errorf("\t Get expected %v : %v %v", want, v, ok)
+			}
+		}
+		if h.Size() != 0 {
+			t.Errorf("\t Size after removing all values expected 0 : %v", h.Size())
+		}
+	}
+
+	t.Log("Given the need to test Fix after a value changes in place")
+	{
+		type task struct{ priority int }
+		h := NewHeap(10, func(a, b *task) int { return cmp.Compare(a.priority, b.priority) })
+		tasks := []*task{{priority: 3}, {priority: 1}, {priority: 2}}
+		handles := make([]*HeapHandle[*task], len(tasks))
+		for i, tk := range tasks {
+			handles[i] = h.Put(tk)
+		}
+
+		tasks[0].priority = 0
+		if !h.Fix(handles[0]) {
+			t.Errorf("\t Fix on a live handle should succeed")
+		}
+		if v, _ := h.Peek(); v != tasks[0] {
+			t.Errorf("\t Peek after Fix expected priority 0 : %v", v.priority)
+		}
+
+		tasks[0].priority = 9
+		h.Fix(handles[0])
+		for _, want := range []int{1, 2, 9} {
+			if v, ok := h.Get(); !ok || v.priority != want {
+				t.Errorf("\t Get expected priority %v : %v", want, v.priority)
+			}
+		}
+	}
+}
+
+func TestHeapBulk(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		data        []int
+		extra       []int
+		compareFunc func(i, j int) int
+	}{
+		{data: []int{9, 4, 7, 1, 8, 2, 6, 3, 5}, extra: []int{0, 10}, compareFunc: SortAscendingInt},
+		{data: []int{2, 1, 4, 4, 5}, extra: []int{3, 3, 9, 0, 7, 6}, compareFunc: SortDescendingInt},
+		{data: []int{}, extra: []int{2, 1}, compareFunc: SortAscendingInt},
+		{data: []int{1}, extra: []int{}, compareFunc: SortAscendingInt},
+	}
+
+	t.Log("Given the need to test bulk Heap construction and merging")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing source data %v ", i, test.data)
+			{
+				all := slices.Concat(test.data, test.extra)
+				slices.SortFunc(all, test.compareFunc)
+				sorted := slices.Clone(test.data)
+				slices.SortFunc(sorted, test.compareFunc)
+
+				h := NewHeapFromSlice(test.data, test.compareFunc)
+				if values := slices.Collect(h.All()); !slices.Equal(values, sorted) {
+					t.Errorf("\t%d\t NewHeapFromSlice should yield values in heap order %v : %v", i, sorted, values)
+				}
+
+				c := h.Clone()
+				c.PutAll(test.extra)
+				if h.Size() != len(test.data) {
+					t.Errorf("\t%d\t PutAll on a clone should not change the original %v : %v", i, len(test.data), h.Size())
+				}
+				if values := c.Drain(); !slices.Equal(values, all) {
+					t.Errorf("\t%d\t Drain after PutAll expected %v : %v", i, all, values)
+				}
+				if c.Size() != 0 {
+					t.Errorf("\t%d\t Drain should empty the heap : %v", i, c.Size())
+				}
+
+				other := NewHeap(len(test.extra), test.compareFunc)
+				for _, n := range test.extra {
+					other.Put(n)
+				}
+				h.Merge(other)
+				if other.Size() != len(test.extra) {
+					t.Errorf("\t%d\t Merge should not change the other heap %v : %v", i, len(test.extra), other.Size())
+				}
+				if values := h.Drain(); !slices.Equal(values, all) {
+					t.Errorf("\t%d\t Drain after Merge expected %v : %v", i, all, values)
+				}
+			}
+		}
+	}
+
+	t.Log("Given the need to test merging a heap with itself")
+	{
+		h := NewHeapFromSlice([]int{3, 1, 2}, SortAscendingInt)
+		h.Merge(h)
+		expected := []int{1, 1, 2, 2, 3, 3}
+		if values := h.Drain(); !slices.Equal(values, expected) {
+			t.Errorf("\t Drain after self Merge expected %v : %v", expected, values)
+		}
+	}
+
+	t.Log("Given the need to test handles after bulk operations")
+	{
+		h := NewHeapFromSlice([]int{5, 6, 7}, SortAscendingInt)
+		handle := h.Put(8)
+		h.PutAll([]int{1, 2, 3, 4, 9, 10})
+		if !h.Update(handle, 0) {
+			t.Errorf("\t Update should succeed on a handle after PutAll")
+		}
+		if v, _ := h.Peek(); v != 0 {
+			t.Errorf("\t Peek after Update expected 0 : %v", v)
+		}
+		if h.Clone().Fix(handle) {
+			t.Errorf("\t Handles should not be valid for a clone")
+		}
+	}
+}
+
+// Used to run the same benchmark against each PriorityQueue
+func benchmarkPriorityQueue[handle any](b *testing.B, q PriorityQueue[int, handle], values []int) {
+	for _, v := range values {
+		q.Put(v)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		v, _ := q.Get()
+		q.Put(v + values[i%len(values)])
+	}
+}
+
+// Used to run the same merge benchmark against each PriorityQueue
+func benchmarkPriorityQueueMerge[q any](b *testing.B, create func() q, put func(q, int), merge func(q, q)) {
+	b.ReportAllocs()
+	for range b.N {
+		into := create()
+		for range 64 {
+			from := create()
+			for n := range 64 {
+				put(from, n)
+			}
+			merge(into, from)
+		}
+	}
+}
+
+// Benchmark the priority queue implementations against each other
+func BenchmarkPriorityQueues(b *testing.B) {
+	const size = 1 << 16
+
+	values := rand.New(rand.NewSource(1)).Perm(size)
+
+	b.Run("PutGet/Heap", func(b *testing.B) {
+		benchmarkPriorityQueue(b, NewHeap(size, SortAscendingInt), values)
+	})
+	for _, arity := range []int{4, 8} {
+		b.Run("PutGet/DaryHeap"+strconv.Itoa(arity), func(b *testing.B) {
+			benchmarkPriorityQueue(b, NewDaryHeap(arity, size, SortAscendingInt), values)
+		})
+	}
+	b.Run("PutGet/PairingHeap", func(b *testing.B) {
+		benchmarkPriorityQueue(b, NewPairingHeap(SortAscendingInt), values)
+	})
+
+	b.Run("Merge/Heap", func(b *testing.B) {
+		benchmarkPriorityQueueMerge(b,
+			func() *Heap[int] { return NewHeap(0, SortAscendingInt) },
+			func(h *Heap[int], v int) { h.Put(v) },
+			(*Heap[int]).Merge)
+	})
+	b.Run("Merge/DaryHeap4", func(b *testing.B) {
+		benchmarkPriorityQueueMerge(b,
+			func() *DaryHeap[int] { return NewDaryHeap(4, 0, SortAscendingInt) },
+			func(h *DaryHeap[int], v int) { h.Put(v) },
+			func(into *DaryHeap[int], from *DaryHeap[int]) { into.Merge(&from.Heap) })
+	})
+	b.Run("Merge/PairingHeap", func(b *testing.B) {
+		benchmarkPriorityQueueMerge(b,
+			func() *PairingHeap[int] { return NewPairingHeap(SortAscendingInt) },
+			func(h *PairingHeap[int], v int) { h.Put(v) },
+			(*PairingHeap[int]).Merge)
+	})
+}
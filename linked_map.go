@@ -0,0 +1,216 @@
+package godatastructures
+
+import (
+	"iter"
+	"sync"
+)
+
+// Map which iterates in insertion order, or optionally in access order
+// (least recently accessed first), by threading its entries through a
+// doubly linked list
+type LinkedMap[key comparable, val comparable] struct {
+	mutex       sync.RWMutex
+	accessOrder bool
+	values      *Map[key, *Node[MapEntry[key, val]]]
+	list        *List[MapEntry[key, val]]
+}
+
+// constructor
+// In access order mode Get and Put move the entry to the back
+func NewLinkedMap[key comparable, val comparable](accessOrder bool) *LinkedMap[key, val] {
+	m := LinkedMap[key, val]{
+		accessOrder: accessOrder,
+		values:      NewMap[key, *Node[MapEntry[key, val]]](1),
+		list:        NewList[MapEntry[key, val]](),
+	}
+	return &m
+}
+
+// Returns the number of key-value mappings in this map
+func (m *LinkedMap[key, val]) Size() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.list.Size()
+}
+
+// Returns the value to which the specified key is mapped
+// boolean ok indicates whether the value was present
+// In access order mode the entry is moved to the back
+func (m *LinkedMap[key, val]) Get(k key) (value val, ok bool) {
+	if m.accessOrder {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+	} else {
+		m.mutex.RLock()
+		defer m.mutex.RUnlock()
+	}
+
+	node, ok := m.values.Get(k)
+	if !ok {
+		return
+	}
+	if m.accessOrder {
+		m.list.ToLast(node)
+	}
+	return node.value.value, true
+}
+
+// Returns true if this map contains a mapping for the specified key.
+// Does not affect access order
+func (m *LinkedMap[key, val]) ContainsKey(k key) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.values.ContainsKey(k)
+}
+
+// Associates the specified value with the specified key in this map.
+// A new key is added at the back. An existing key keeps its position,
+// unless in access order mode, where it is moved to the back
+func (m *LinkedMap[key, val]) Put(k key, v val) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if node, ok := m.values.Get(k); ok {
+		node.value.value = v
+		if m.accessOrder {
+			m.list.ToLast(node)
+		}
+		return
+	}
+
+	m.list.AddLast(MapEntry[key, val]{key: k, value: v})
+	m.values.Put(k, m.list.last)
+}
+
+// Removes the mapping for the specified key from this map if present
+func (m *LinkedMap[key, val]) Remove(k key) (ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, ok := m.values.Get(k)
+	if ok {
+		m.values.Remove(k)
+		m.list.Unlink(node)
+	}
+	return
+}
+
+// Removes all of the mappings from this map
+func (m *LinkedMap[key, val]) Clear() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.values.Clear()
+	m.list.Clear()
+}
+
+// Returns the first key-value mapping
+// boolean ok indicates whether the map was non-empty
+func (m *LinkedMap[key, val]) First() (k key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.list.first == nil {
+		return
+	}
+	return m.list.first.value.key, m.list.first.value.value, true
+}
+
+// Returns the last key-value mapping
+// boolean ok indicates whether the map was non-empty
+func (m *LinkedMap[key, val]) Last() (k key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.list.last == nil {
+		return
+	}
+	return m.list.last.value.key, m.list.last.value.value, true
+}
+
+// Moves the mapping for the key to the front
+// Returns false if the key is not present
+func (m *LinkedMap[key, val]) MoveToFront(k key) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, ok := m.values.Get(k)
+	if ok {
+		m.list.ToFirst(node)
+	}
+	return ok
+}
+
+// Moves the mapping for the key to the back
+// Returns false if the key is not present
+func (m *LinkedMap[key, val]) MoveToBack(k key) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	node, ok := m.values.Get(k)
+	if ok {
+		m.list.ToLast(node)
+	}
+	return ok
+}
+
+// Returns an iterator over the key-value mappings from first to last.
+// The mappings are copied under the read lock when iteration starts,
+// so the loop body may safely call any method of the map, including
+// those modifying it
+func (m *LinkedMap[key, val]) All() iter.Seq2[key, val] {
+	return func(yield func(key, val) bool) {
+		for _, e := range m.snapshot() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the key-value mappings from last to first,
+// from a copy taken as for All
+func (m *LinkedMap[key, val]) Backward() iter.Seq2[key, val] {
+	return func(yield func(key, val) bool) {
+		entries := m.snapshot()
+		for i := len(entries) - 1; i >= 0; i-- {
+			if !yield(entries[i].key, entries[i].value) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the keys from first to last,
+// from a copy taken as for All
+func (m *LinkedMap[key, val]) Keys() iter.Seq[key] {
+	return func(yield func(key) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the values from first to last,
+// from a copy taken as for All
+func (m *LinkedMap[key, val]) Values() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Used internally to copy the mappings in order, under the read lock
+func (m *LinkedMap[key, val]) snapshot() []MapEntry[key, val] {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.list.Slice()
+}
@@ -0,0 +1,211 @@
+package godatastructures
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLinkedMap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source []string
+	}{
+		{source: []string{"one", "two", "three", "four", "five"}},
+		{source: []string{"c", "a", "b"}},
+		{source: []string{"single"}},
+		{source: []string{}},
+	}
+
+	t.Log("Given the need to test LinkedMap insertion order behaviour")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing source data %v ", i, test.source)
+			{
+				m := NewLinkedMap[string, int](false)
+
+				if _, _, ok := m.First(); ok {
+					t.Errorf("\t%d\t First on empty map returned a value", i)
+				}
+				if _, _, ok := m.Last(); ok {
+					t.Errorf("\t%d\t Last on empty map returned a value", i)
+				}
+
+				for n, k := range test.source {
+					m.Put(k, n)
+				}
+
+				if m.Size() != len(test.source) {
+					t.Errorf("\t%d\t Size expected %v : %v", i, len(test.source), m.Size())
+				}
+
+				keys := slices.Collect(m.Keys())
+				if !slices.Equal(keys, test.source) {
+					t.Errorf("\t%d\t Keys should be in insertion order %v : %v", i, test.source, keys)
+				}
+
+				reversed := slices.Clone(test.source)
+				slices.Reverse(reversed)
+				var backward []string
+				for k := range m.Backward() {
+					backward = append(backward, k)
+				}
+				if !slices.Equal(backward, reversed) {
+					t.Errorf("\t%d\t Backward should be in reverse insertion order %v : %v", i, reversed, backward)
+				}
+
+				if len(test.source) == 0 {
+					continue
+				}
+
+				t.Logf("\t%d\t Testing Put on an existing key keeps its position", i)
+
+				m.Put(test.source[0], 100)
+				if k, v, _ := m.First(); k != test.source[0] || v != 100 {
+					t.Errorf("\t%d\t First expected %v, 100 : %v, %v", i, test.source[0], k, v)
+				}
+
+				t.Logf("\t%d\t Testing MoveToBack and MoveToFront", i)
+
+				m.MoveToBack(test.source[0])
+				if k, _, _ := m.Last(); k != test.source[0] {
+					t.Errorf("\t%d\t Last after MoveToBack expected %v : %v", i, test.source[0], k)
+				}
+				last := test.source[len(test.source)-1]
+				m.MoveToFront(last)
+				if k, _, _ := m.First(); k != last {
+					t.Errorf("\t%d\t First after MoveToFront expected %v : %v", i, last, k)
+				}
+				if m.MoveToFront("missing") || m.MoveToBack("missing") {
+					t.Errorf("\t%d\t Moving a missing key should return false", i)
+				}
+
+				t.Logf("\t%d\t Testing Remove", i)
+
+				if !m.Remove(last) || m.ContainsKey(last) {
+					t.Errorf("\t%d\t Remove should remove %v", i, last)
+				}
+				if m.Size() != len(test.source)-1 {
+					t.Errorf("\t%d\t Size after Remove expected %v : %v", i, len(test.source)-1, m.Size())
+				}
+				if m.Remove(last) {
+					t.Errorf("\t%d\t Second Remove of %v should return false", i, last)
+				}
+
+				m.Clear()
+				if m.Size() != 0 || len(slices.Collect(m.Keys())) != 0 {
+					t.Errorf("\t%d\t Clear should empty the map", i)
+				}
+			}
+		}
+	}
+}
+
+func TestLinkedMapAccessOrder(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test LinkedMap access order behaviour")
+	{
+		m := NewLinkedMap[string, int](true)
+		for n, k := range []string{"a", "b", "c", "d"} {
+			m.Put(k, n)
+		}
+
+		m.Get("a")
+		m.Put("b", 10)
+		m.ContainsKey("c") // does not affect order
+
+		expected := []string{"c", "d", "a", "b"}
+		keys := slices.Collect(m.Keys())
+		if !slices.Equal(keys, expected) {
+			t.Errorf("\t Keys should be in access order %v : %v", expected, keys)
+		}
+
+		values := slices.Collect(m.Values())
+		if !slices.Equal(values, []int{2, 3, 0, 10}) {
+			t.Errorf("\t Values should be in access order %v", values)
+		}
+	}
+}
+
+func TestLinkedMapConcurrent(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test LinkedMap concurrently")
+	{
+		concurrency := 50
+		m := NewLinkedMap[int, int](true)
+
+		wg := sync.WaitGroup{}
+		wg.Add(concurrency)
+		for g := range concurrency {
+			go func() {
+				defer wg.Done()
+				for i := range 100 {
+					k := (g + i) % 20
+					m.Put(k, i)
+					m.Get(k)
+					if i%3 == 0 {
+						m.Remove(k)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		keys := slices.Collect(m.Keys())
+		if len(keys) != m.Size() {
+			t.Errorf("\t List and map are inconsistent %v : %v", m.Size(), len(keys))
+		}
+		for _, k := range keys {
+			if !m.ContainsKey(k) {
+				t.Errorf("\t Key %v in list but not in map", k)
+			}
+		}
+	}
+}
+
+func TestLinkedMapIterationCalls(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test calling the map from inside iteration")
+	{
+		for _, accessOrder := range []bool{false, true} {
+			m := NewLinkedMap[int, int](accessOrder)
+			for k := range 10 {
+				m.Put(k, k)
+			}
+
+			done := make(chan []int)
+			go func() {
+				seen := []int{}
+				for k, v := range m.All() {
+					seen = append(seen, k)
+					m.Get(k)
+					m.ContainsKey(k)
+					m.Size()
+					m.Put(k+100, v)
+					m.Remove(k)
+				}
+				for range m.Backward() {
+					m.MoveToFront(100)
+				}
+				done <- seen
+			}()
+
+			select {
+			case seen := <-done:
+				if !slices.Equal(seen, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+					t.Errorf("\t All should yield the mappings as iteration started : %v", seen)
+				}
+				if m.Size() != 10 || m.ContainsKey(0) || !m.ContainsKey(109) {
+					t.Errorf("\t Changes made during iteration should apply : %v", slices.Collect(m.Keys()))
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("\t Calling the map from inside iteration deadlocked, access order %v", accessOrder)
+			}
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package godatastructures
 
+import "iter"
+
 // Generic Doubly linked list
 type List[val comparable] struct {
 	size  int
@@ -435,3 +437,33 @@ func (l *List[val]) DoReverse(f func(v val)) {
 		f(curr.value)
 	}
 }
+
+// Return an iterator over the list values from first to last.
+// The current node may be unlinked by the loop body, but other
+// modifications during iteration have undefined results
+func (l *List[val]) All() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		for curr := l.first; curr != nil; {
+			next := curr.next
+			if !yield(curr.value) {
+				return
+			}
+			curr = next
+		}
+	}
+}
+
+// Return an iterator over the list values from last to first.
+// The current node may be unlinked by the loop body, but other
+// modifications during iteration have undefined results
+func (l *List[val]) Backward() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		for curr := l.last; curr != nil; {
+			prev := curr.prev
+			if !yield(curr.value) {
+				return
+			}
+			curr = prev
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestList(t *testing.T) {
@@ -589,3 +590,59 @@ func TestListSingleItem(t *testing.T) {
 	}
 
 }
+
+func TestListIterators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source []int
+	}{
+		{source: []int{1, 2, 3, 4, 5}},
+		{source: []int{10, 24, 6, 24, 3, 2}},
+		{source: []int{10}},
+		{source: []int{}},
+	}
+
+	t.Log("Given the need to test list iterators on sample data")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing source data %v ", i, test.source)
+			{
+				l := NewList[int]()
+				l.FromSlice(test.source)
+
+				forward := slices.Collect(l.All())
+				if !cmp.Equal(forward, l.Slice(), cmpopts.EquateEmpty()) {
+					t.Errorf("\t%d\t All should match Slice %v : %v", i, l.Slice(), forward)
+				}
+
+				backward := slices.Collect(l.Backward())
+				if !cmp.Equal(backward, l.ReverseSlice(), cmpopts.EquateEmpty()) {
+					t.Errorf("\t%d\t Backward should match ReverseSlice %v : %v", i, l.ReverseSlice(), backward)
+				}
+
+				t.Logf("\t%d\t Testing breaking out of iteration", i)
+
+				count := 0
+				for range l.All() {
+					count++
+					break
+				}
+				if count != min(1, len(test.source)) {
+					t.Errorf("\t%d\t Break should stop iteration after one value : %d", i, count)
+				}
+
+				t.Logf("\t%d\t Testing unlinking the current node during iteration", i)
+
+				for v := range l.All() {
+					if n, ok := l.FindFirst(v); ok {
+						l.Unlink(n)
+					}
+				}
+				if l.Size() != 0 {
+					t.Errorf("\t%d\t Unlinking every node during iteration should empty the list : %v", i, l.Slice())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package godatastructures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Function loading the value for a key missing from a LoadingCache
+type Loader[key comparable, val any] func(ctx context.Context, k key) (val, error)
+
+// Returned by NewLoadingCacheWithOptions when an argument or option has
+// an invalid value
+var ErrInvalidLoadingOption = errors.New("loading cache: invalid option")
+
+// Wrapped by the error returned for a load in which the Loader panicked
+var ErrLoaderPanicked = errors.New("loading cache: loader panicked")
+
+// Option used to configure a LoadingCache at construction
+type LoadingOption func(*loadingConfig) error
+
+// Settings collected from the options supplied to NewLoadingCache
+type loadingConfig struct {
+	negativeTTL time.Duration // zero if errors are not cached
+}
+
+// Cache the errors returned by the Loader for the duration, so keys
+// failing to load are not retried on every Get. Must be positive
+func WithNegativeTTL(ttl time.Duration) LoadingOption {
+	return func(c *loadingConfig) error {
+		if ttl <= 0 {
+			return fmt.Errorf("%w: negative TTL %v must be positive", ErrInvalidLoadingOption, ttl)
+		}
+		c.negativeTTL = ttl
+		return nil
+	}
+}
+
+// Cache which loads missing values with a Loader, storing them in a
+// wrapped LRUCache. Concurrent misses for the same key share a single
+// load, and Refresh reloads a value in the background while the stale
+// value is still served.
+// The load runs in its own goroutine with a context keeping the values
+// of the first caller's context, but not cancelled with it, so a caller
+// giving up does not fail the others waiting on the same load
+type LoadingCache[key comparable, val any] struct {
+	cache    *LRUCache[key, val]
+	failures *LRUCache[key, error] // errors cached for the negative TTL, nil without one
+	loader   Loader[key, val]
+	mutex    sync.Mutex
+	calls    map[key]*loadCall[val] // loads in flight, guarded by mutex
+}
+
+// A load in flight, shared by every caller missing the key meanwhile
+type loadCall[val any] struct {
+	done  chan struct{} // closed once value and err are set
+	value val
+	err   error
+}
+
+// constructor, wrapping the cache, which may be configured with any
+// LRUOption and should not then be written to other than through the
+// LoadingCache
+// ok is false if the cache or loader is nil, or any option is invalid
+func NewLoadingCache[key comparable, val any](cache *LRUCache[key, val], loader Loader[key, val], options ...LoadingOption) (loading *LoadingCache[key, val], ok bool) {
+	loading, err := NewLoadingCacheWithOptions(cache, loader, options...)
+	return loading, err == nil
+}
+
+// constructor, as for NewLoadingCache. Cached errors are held in a cache
+// of the same capacity as the wrapped cache, and expire by its clock
+// Returns an error wrapping ErrInvalidLoadingOption if the cache or
+// loader is nil, or any option is invalid
+func NewLoadingCacheWithOptions[key comparable, val any](cache *LRUCache[key, val], loader Loader[key, val], options ...LoadingOption) (*LoadingCache[key, val], error) {
+
+	if cache == nil {
+		return nil, fmt.Errorf("%w: cache must not be nil", ErrInvalidLoadingOption)
+	}
+	if loader == nil {
+		return nil, fmt.Errorf("%w: loader must not be nil", ErrInvalidLoadingOption)
+	}
+
+	config := loadingConfig{}
+	for _, option := range options {
+		if err := option(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	loading := &LoadingCache[key, val]{
+		cache:  cache,
+		loader: loader,
+		calls:  map[key]*loadCall[val]{},
+	}
+	if config.negativeTTL > 0 {
+		failures, err := NewLRUCacheWithOptions[key, error](cache.Cap(), WithDefaultTTL(config.negativeTTL), WithClock(cache.clock))
+		if err != nil {
+			return nil, err
+		}
+		loading.failures = failures
+	}
+	return loading, nil
+}
+
+// Return the value for the key, loading it if missing, or waiting for
+// the load already in flight for the key. Returns the error of the load,
+// or a cached error within the negative TTL, or the context's error if
+// it is done before the load completes
+func (c *LoadingCache[key, val]) Get(ctx context.Context, k key) (value val, err error) {
+	if value, ok := c.cache.Get(k); ok {
+		return value, nil
+	}
+	if c.failures != nil {
+		if err, ok := c.failures.Get(k); ok {
+			return value, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return value, err
+	}
+
+	call := c.load(ctx, k, false)
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return value, ctx.Err()
+	}
+}
+
+// Reload the value for the key in the background, unless a load is
+// already in flight, returning immediately. Get keeps returning the
+// stale value until the load completes. If the load fails the stale
+// value is kept, and the error is only cached if there was none
+func (c *LoadingCache[key, val]) Refresh(k key) {
+	c.load(context.Background(), k, true)
+}
+
+// Remove the value and any cached error for the key, so the next Get
+// loads it. A load already in flight still stores its result
+func (c *LoadingCache[key, val]) Invalidate(k key) {
+	c.cache.Remove(k)
+	if c.failures != nil {
+		c.failures.Remove(k)
+	}
+}
+
+// Return the current length of the wrapped cache
+func (c *LoadingCache[key, val]) Len() int {
+	return c.cache.Len()
+}
+
+// Used internally to start a load of the key, or join the one in flight.
+// A Get may miss the cache just before a load stores its result and
+// arrive here just after the load is forgotten, so unless refreshing,
+// the caches are checked again under the mutex the load is forgotten under
+func (c *LoadingCache[key, val]) load(ctx context.Context, k key, refresh bool) *loadCall[val] {
+	c.mutex.Lock()
+	if call, ok := c.calls[k]; ok {
+		c.mutex.Unlock()
+		return call
+	}
+	if !refresh {
+		if call, ok := c.stored(k); ok {
+			c.mutex.Unlock()
+			return call
+		}
+	}
+	call := &loadCall[val]{done: make(chan struct{})}
+	c.calls[k] = call
+	c.mutex.Unlock()
+
+	go c.run(context.WithoutCancel(ctx), k, call, refresh)
+	return call
+}
+
+// Used internally to find the value or error stored for the key, as a
+// completed call. The caches are peeked, so no OnEvict hook is called
+// while the mutex is held
+// Must be called with the mutex held
+func (c *LoadingCache[key, val]) stored(k key) (*loadCall[val], bool) {
+	call := &loadCall[val]{done: make(chan struct{})}
+	close(call.done)
+
+	if value, ok := c.cache.peek(k); ok {
+		call.value = value
+		return call, true
+	}
+	if c.failures != nil {
+		if err, ok := c.failures.peek(k); ok {
+			call.err = err
+			return call, true
+		}
+	}
+	return nil, false
+}
+
+// Used internally to call the loader and store its result. The result is
+// stored before the call is forgotten under the mutex, so a load of the
+// key starting after finds it
+func (c *LoadingCache[key, val]) run(ctx context.Context, k key, call *loadCall[val], refresh bool) {
+	defer func() {
+		c.mutex.Lock()
+		delete(c.calls, k)
+		c.mutex.Unlock()
+
+		close(call.done)
+	}()
+
+	call.value, call.err = c.callLoader(ctx, k)
+	switch {
+	case call.err == nil:
+		c.cache.Put(k, call.value)
+		if c.failures != nil {
+			c.failures.Remove(k)
+		}
+	case c.failures != nil && !(refresh && c.cache.Contains(k)):
+		c.failures.Put(k, call.err)
+	}
+}
+
+// Used internally to call the loader, turning a panic into an error, as
+// it would otherwise end the program from the load's goroutine
+func (c *LoadingCache[key, val]) callLoader(ctx context.Context, k key) (value val, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrLoaderPanicked, r)
+		}
+	}()
+	return c.loader(ctx, k)
+}
@@ -0,0 +1,211 @@
+package godatastructures
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadingCache(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test concurrent misses sharing a single load")
+	{
+		var loads atomic.Int32
+		started, release := make(chan struct{}), make(chan struct{})
+		lru, _ := NewLRUCache[string, int](10)
+		c, ok := NewLoadingCache(lru, func(ctx context.Context, k string) (int, error) {
+			if loads.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return len(k), nil
+		})
+		if !ok {
+			t.Fatalf("\t Create loading cache failed")
+		}
+
+		const callers = 20
+		results := make(chan int, callers)
+		var wg sync.WaitGroup
+		for range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := c.Get(context.Background(), "four")
+				if err != nil {
+					t.Errorf("\t Get failed : %v", err)
+				}
+				results <- v
+			}()
+		}
+		<-started
+		close(release)
+		wg.Wait()
+		close(results)
+
+		for v := range results {
+			if v != 4 {
+				t.Errorf("\t Get expected 4 : %v", v)
+			}
+		}
+		if loads.Load() != 1 {
+			t.Errorf("\t Concurrent misses expected a single load : %v", loads.Load())
+		}
+		if v, ok := lru.Get("four"); !ok || v != 4 {
+			t.Errorf("\t Loaded value should be in the wrapped cache : %v %v", v, ok)
+		}
+	}
+
+	t.Log("Given the need to test a caller giving up on a load")
+	{
+		release := make(chan struct{})
+		lru, _ := NewLRUCache[string, int](10)
+		c, _ := NewLoadingCache(lru, func(ctx context.Context, k string) (int, error) {
+			<-release
+			return 1, ctx.Err()
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			_, err := c.Get(ctx, "k")
+			done <- err
+		}()
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("\t Get with a cancelled context expected context.Canceled : %v", err)
+		}
+
+		close(release)
+		if v, err := c.Get(context.Background(), "k"); err != nil || v != 1 {
+			t.Errorf("\t The load should complete for other callers : %v %v", v, err)
+		}
+	}
+
+	t.Log("Given the need to test loader errors")
+	{
+		var loads atomic.Int32
+		failure := errors.New("unavailable")
+		loader := func(ctx context.Context, k string) (int, error) {
+			loads.Add(1)
+			return 0, failure
+		}
+
+		lru, _ := NewLRUCache[string, int](10)
+		c, _ := NewLoadingCache(lru, loader)
+		c.Get(context.Background(), "k")
+		if _, err := c.Get(context.Background(), "k"); !errors.Is(err, failure) || loads.Load() != 2 {
+			t.Errorf("\t Errors should not be cached without a negative TTL : %v %v", err, loads.Load())
+		}
+
+		loads.Store(0)
+		clock := newFakeClock()
+		lru, _ = NewLRUCache[string, int](10, WithClock(clock))
+		c, ok := NewLoadingCache(lru, loader, WithNegativeTTL(time.Minute))
+		if !ok {
+			t.Fatalf("\t Create loading cache with a negative TTL failed")
+		}
+		c.Get(context.Background(), "k")
+		if _, err := c.Get(context.Background(), "k"); !errors.Is(err, failure) || loads.Load() != 1 {
+			t.Errorf("\t Errors should be cached for the negative TTL : %v %v", err, loads.Load())
+		}
+		clock.Advance(time.Minute)
+		if _, err := c.Get(context.Background(), "k"); !errors.Is(err, failure) || loads.Load() != 2 {
+			t.Errorf("\t A cached error should expire after the negative TTL : %v %v", err, loads.Load())
+		}
+		c.Invalidate("k")
+		if c.Get(context.Background(), "k"); loads.Load() != 3 {
+			t.Errorf("\t Invalidate should remove a cached error : %v", loads.Load())
+		}
+
+		if _, err := NewLoadingCacheWithOptions(lru, loader, WithNegativeTTL(0)); !errors.Is(err, ErrInvalidLoadingOption) {
+			t.Errorf("\t Zero negative TTL expected ErrInvalidLoadingOption : %v", err)
+		}
+		if _, err := NewLoadingCacheWithOptions(nil, loader, WithNegativeTTL(time.Minute)); !errors.Is(err, ErrInvalidLoadingOption) {
+			t.Errorf("\t Nil cache expected ErrInvalidLoadingOption : %v", err)
+		}
+		if _, ok := NewLoadingCache[string, int](lru, nil); ok {
+			t.Errorf("\t Nil loader should fail to create a loading cache")
+		}
+	}
+
+	t.Log("Given the need to test a load starting after a result was stored")
+	{
+		var loads atomic.Int32
+		failure := errors.New("unavailable")
+		lru, _ := NewLRUCache[string, int](10)
+		c, _ := NewLoadingCache(lru, func(ctx context.Context, k string) (int, error) {
+			loads.Add(1)
+			return 0, nil
+		}, WithNegativeTTL(time.Minute))
+
+		// as if a load stored these between a Get missing and starting its own
+		lru.Put("value", 1)
+		c.failures.Put("error", failure)
+
+		call := c.load(context.Background(), "value", false)
+		<-call.done
+		if call.value != 1 || call.err != nil {
+			t.Errorf("\t Load should return the stored value 1 : %v %v", call.value, call.err)
+		}
+		call = c.load(context.Background(), "error", false)
+		<-call.done
+		if !errors.Is(call.err, failure) {
+			t.Errorf("\t Load should return the stored error : %v", call.err)
+		}
+		if loads.Load() != 0 {
+			t.Errorf("\t Stored results should not be loaded again : %v", loads.Load())
+		}
+	}
+
+	t.Log("Given the need to test a loader panicking")
+	{
+		lru, _ := NewLRUCache[string, int](10)
+		c, _ := NewLoadingCache(lru, func(ctx context.Context, k string) (int, error) {
+			panic("broken")
+		})
+		if _, err := c.Get(context.Background(), "k"); !errors.Is(err, ErrLoaderPanicked) {
+			t.Errorf("\t A panicking loader expected ErrLoaderPanicked : %v", err)
+		}
+	}
+
+	t.Log("Given the need to test refreshing a value in the background")
+	{
+		var version atomic.Int32
+		release := make(chan struct{})
+		lru, _ := NewLRUCache[string, int32](10)
+		c, _ := NewLoadingCache(lru, func(ctx context.Context, k string) (int32, error) {
+			v := version.Add(1)
+			if v > 1 {
+				<-release
+			}
+			return v, nil
+		})
+
+		if v, _ := c.Get(context.Background(), "k"); v != 1 {
+			t.Errorf("\t First Get expected version 1 : %v", v)
+		}
+
+		c.Refresh("k")
+		c.Refresh("k") // joins the refresh in flight
+		c.mutex.Lock()
+		call := c.calls["k"]
+		c.mutex.Unlock()
+
+		if v, _ := c.Get(context.Background(), "k"); v != 1 {
+			t.Errorf("\t Get during a refresh expected the stale version 1 : %v", v)
+		}
+		close(release)
+		<-call.done
+		if v, _ := c.Get(context.Background(), "k"); v != 2 {
+			t.Errorf("\t Get after a refresh expected version 2 : %v", v)
+		}
+		if version.Load() != 2 {
+			t.Errorf("\t Concurrent refreshes expected a single load : %v", version.Load())
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package godatastructures
 
-import "sync"
+import (
+	"iter"
+	"sync"
+)
 
 // Generic Doubly linked list
 type LList[val comparable] struct {
@@ -586,3 +589,35 @@ func (l *LList[val]) DoReverse(f func(v val)) {
 		f(curr.value)
 	}
 }
+
+// Return an iterator over the list values from first to last.
+// The list read lock is held until iteration finishes, so the loop
+// body must NOT modify the list (as with Do)
+func (l *LList[val]) All() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		l.mutex.RLock()
+		defer l.mutex.RUnlock()
+
+		for curr := l.first; curr != nil; curr = curr.next {
+			if !yield(curr.value) {
+				return
+			}
+		}
+	}
+}
+
+// Return an iterator over the list values from last to first.
+// The list read lock is held until iteration finishes, so the loop
+// body must NOT modify the list (as with DoReverse)
+func (l *LList[val]) Backward() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		l.mutex.RLock()
+		defer l.mutex.RUnlock()
+
+		for curr := l.last; curr != nil; curr = curr.prev {
+			if !yield(curr.value) {
+				return
+			}
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 var concurrency = 5000 // must be divisible by 4
@@ -733,3 +734,47 @@ func TestListConcurrentBeforeAfter(t *testing.T) {
 
 	}
 }
+
+func TestLockingListIterators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source []int
+	}{
+		{source: []int{1, 2, 3, 4, 5}},
+		{source: []int{10, 24, 6, 24, 3, 2}},
+		{source: []int{10}},
+		{source: []int{}},
+	}
+
+	t.Log("Given the need to test locking list iterators on sample data")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing source data %v ", i, test.source)
+			{
+				l := NewLList[int]()
+				l.FromSlice(test.source)
+
+				forward := slices.Collect(l.All())
+				if !cmp.Equal(forward, l.Slice(), cmpopts.EquateEmpty()) {
+					t.Errorf("\t%d\t All should match Slice %v : %v", i, l.Slice(), forward)
+				}
+
+				backward := slices.Collect(l.Backward())
+				if !cmp.Equal(backward, l.ReverseSlice(), cmpopts.EquateEmpty()) {
+					t.Errorf("\t%d\t Backward should match ReverseSlice %v : %v", i, l.ReverseSlice(), backward)
+				}
+
+				t.Logf("\t%d\t Testing breaking out of iteration releases the lock", i)
+
+				for range l.Backward() {
+					break
+				}
+				l.AddLast(-1) // would deadlock if the read lock was still held
+				if v, ok := l.PeekLast(); !ok || v != -1 {
+					t.Errorf("\t%d\t AddLast after break expected -1 : %v", i, v)
+				}
+			}
+		}
+	}
+}
@@ -1,43 +1,212 @@
 package godatastructures
 
 import (
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Number of reads an LRUCache buffers before applying them to its list
+const lruReadBuffer = 64
+
 type CacheEntry[key comparable, val any] struct {
-	Key   key
-	Value val
+	Key     key
+	Value   val
+	expires time.Time // zero if the entry never expires
+	weight  int64
 }
 
-// Cache with Least Recently Used eviction policy
+// Function returning the cost of holding a value in an LRUCache,
+// counted against its capacity. Negative weights are treated as 0
+type Weigher[key comparable, val any] func(k key, v val) int64
+
+// The reason an entry left an LRUCache, passed to the OnEvict hook
+type EvictReason int
+
+const (
+	// The least recent entry was evicted to make room for a new one
+	EvictCapacity EvictReason = iota
+	// The entry was removed by Remove
+	EvictRemoved
+	// The entry was removed by Clear
+	EvictCleared
+	// The value was replaced by a Put to the same key
+	EvictReplaced
+	// The entry's time-to-live elapsed
+	EvictExpired
+)
+
+// Returns the name of the reason
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictRemoved:
+		return "removed"
+	case EvictCleared:
+		return "cleared"
+	case EvictReplaced:
+		return "replaced"
+	case EvictExpired:
+		return "expired"
+	}
+	return fmt.Sprintf("EvictReason(%d)", int(r))
+}
+
+// Cache with Least Recently Used eviction policy, and optionally
+// entries which expire after a time-to-live.
+// Capacity is a count of entries, or with a Weigher the total weight.
+// Expired entries are removed when found by Get or Contains, or by
+// a janitor goroutine checking periodically if one is started.
+// Values leaving the cache are passed to the OnEvict hook, if set,
+// after the cache lock is released, so the hook may use the cache.
+//
+// The recency list is only changed with the write lock held. Get takes
+// the read lock, so reads run concurrently, and records the entry it
+// read in a buffer, which is drained into the list by the next write.
+// When the buffer is full a reader drains it if the write lock is free,
+// and otherwise drops the read, so under heavy contention recency is
+// approximate but the list and map always agree
 type LRUCache[key comparable, val any] struct {
-	mutex    sync.RWMutex
-	capacity int
-	values   *Map[key, *LNode[*CacheEntry[key, val]]]
-	list     *LList[*CacheEntry[key, val]]
+	mutex      sync.RWMutex
+	capacity   int
+	values     *Map[key, *Node[*CacheEntry[key, val]]]
+	list       *List[*CacheEntry[key, val]] // most recent first, guarded by the write lock
+	reads      chan *Node[*CacheEntry[key, val]]
+	defaultTTL time.Duration // zero if entries put without a TTL never expire
+	clock      Clock
+	done       chan struct{} // closed to stop the janitor, nil without one
+	close      sync.Once
+	onEvict    func(k key, v val, reason EvictReason)
+	weigher    Weigher[key, val]
+	weight     int64 // the total weight of the entries, guarded by the write lock
+	hits       atomic.Uint64
+	misses     atomic.Uint64
 }
 
-func NewLRUCache[key comparable, val any](cap int) (cache *LRUCache[key, val], ok bool) {
+// Returned by NewLRUCacheWithOptions when an option has an invalid value
+var ErrInvalidLRUOption = errors.New("lru: invalid option")
+
+// Option used to configure an LRUCache at construction
+type LRUOption func(*lruConfig) error
+
+// Settings collected from the options supplied to NewLRUCache
+type lruConfig struct {
+	defaultTTL time.Duration
+	clock      Clock
+	janitor    time.Duration // zero without a janitor
+	weigher    any           // Weigher[key, val] for the types of the cache
+}
+
+// Expire entries put without their own TTL once the duration has
+// elapsed. Must be positive
+func WithDefaultTTL(ttl time.Duration) LRUOption {
+	return func(c *lruConfig) error {
+		if ttl <= 0 {
+			return fmt.Errorf("%w: default TTL %v must be positive", ErrInvalidLRUOption, ttl)
+		}
+		c.defaultTTL = ttl
+		return nil
+	}
+}
+
+// Use the supplied clock for expiry instead of the system clock
+func WithClock(clock Clock) LRUOption {
+	return func(c *lruConfig) error {
+		if clock == nil {
+			return fmt.Errorf("%w: clock must not be nil", ErrInvalidLRUOption)
+		}
+		c.clock = clock
+		return nil
+	}
+}
+
+// Use the supplied function to weigh entries, so that the capacity of
+// the cache is a total weight rather than a count of entries.
+// The key and value types of the weigher must match those of the cache
+func WithWeigher[key comparable, val any](w Weigher[key, val]) LRUOption {
+	return func(c *lruConfig) error {
+		if w == nil {
+			return fmt.Errorf("%w: weigher must not be nil", ErrInvalidLRUOption)
+		}
+		c.weigher = w
+		return nil
+	}
+}
+
+// Start a janitor goroutine removing expired entries at the interval,
+// which runs until Close is called. Must be positive
+func WithJanitor(interval time.Duration) LRUOption {
+	return func(c *lruConfig) error {
+		if interval <= 0 {
+			return fmt.Errorf("%w: janitor interval %v must be positive", ErrInvalidLRUOption, interval)
+		}
+		c.janitor = interval
+		return nil
+	}
+}
+
+// constructor
+// ok is false if cap is less than 1 or any option is invalid
+func NewLRUCache[key comparable, val any](cap int, options ...LRUOption) (cache *LRUCache[key, val], ok bool) {
+	cache, err := NewLRUCacheWithOptions[key, val](cap, options...)
+	return cache, err == nil
+}
+
+// constructor
+// Returns an error wrapping ErrInvalidLRUOption if cap is less than 1
+// or any option is invalid
+func NewLRUCacheWithOptions[key comparable, val any](cap int, options ...LRUOption) (*LRUCache[key, val], error) {
 
 	if cap < 1 {
-		return cache, false
+		return nil, fmt.Errorf("%w: capacity %d must be at least 1", ErrInvalidLRUOption, cap)
 	}
 
-	cache = &LRUCache[key, val]{
-		mutex:    sync.RWMutex{},
-		capacity: cap,
-		values:   NewMap[key, *LNode[*CacheEntry[key, val]]](1),
-		list:     NewLList[*CacheEntry[key, val]](),
+	config := lruConfig{
+		clock: systemClock{},
+	}
+	for _, option := range options {
+		if err := option(&config); err != nil {
+			return nil, err
+		}
 	}
-	return cache, true
+
+	weigher := func(key, val) int64 { return 1 }
+	if config.weigher != nil {
+		w, ok := config.weigher.(Weigher[key, val])
+		if !ok {
+			return nil, fmt.Errorf("%w: weigher %T does not match types of LRUCache[%T, %T]", ErrInvalidLRUOption, config.weigher, *new(key), *new(val))
+		}
+		weigher = w
+	}
+
+	cache := &LRUCache[key, val]{
+		mutex:      sync.RWMutex{},
+		capacity:   cap,
+		values:     NewMap[key, *Node[*CacheEntry[key, val]]](1),
+		list:       NewList[*CacheEntry[key, val]](),
+		reads:      make(chan *Node[*CacheEntry[key, val]], lruReadBuffer),
+		defaultTTL: config.defaultTTL,
+		clock:      config.clock,
+		weigher:    weigher,
+	}
+	if config.janitor > 0 {
+		cache.done = make(chan struct{})
+		go cache.janitor(config.janitor)
+	}
+	return cache, nil
 }
 
-// Return the capacity of the cache
+// Return the capacity of the cache, a count of entries,
+// or with a Weigher the total weight
 func (l *LRUCache[key, val]) Cap() int {
 	return l.capacity
 }
 
-// Return the current length of the cache
+// Return the current length of the cache, including any
+// expired entries not yet removed
 func (l *LRUCache[key, val]) Len() int {
 	l.mutex.RLock()
 	defer l.mutex.RUnlock()
@@ -45,70 +214,326 @@ func (l *LRUCache[key, val]) Len() int {
 	return l.values.Size()
 }
 
-// Check if the key is in the cache.
+// Check if the key is in the cache and has not expired.
 // Does not affect recency
 func (l *LRUCache[key, val]) Contains(k key) bool {
 	l.mutex.RLock()
-	defer l.mutex.RUnlock()
+	existingNode, ok := l.values.Get(k)
+	expired := ok && l.expired(existingNode.value)
+	l.mutex.RUnlock()
 
-	return l.values.ContainsKey(k)
+	if expired {
+		l.expire(k, existingNode)
+		return false
+	}
+	return ok
 }
 
-// Return the value associated with the key
+// Return the value associated with the key, recording a hit or miss,
 // and move that item (if any) to the most recent position
-// boolean ok indicates presence of a value
+// boolean ok indicates presence of a value which has not expired
 func (l *LRUCache[key, val]) Get(k key) (value val, ok bool) {
 	l.mutex.RLock()
-	defer l.mutex.RUnlock() // List manages its own locks
-
 	existingNode, ok := l.values.Get(k)
-	if ok {
-		value = existingNode.value.Value
-		l.list.ToFirst(existingNode)
+	if !ok {
+		l.mutex.RUnlock()
+		l.misses.Add(1)
 		return
 	}
-	ok = false
+	if l.expired(existingNode.value) {
+		l.mutex.RUnlock()
+		l.misses.Add(1)
+		l.expire(k, existingNode)
+		return value, false
+	}
+	value = existingNode.value.Value
+	l.mutex.RUnlock()
 
+	l.hits.Add(1)
+	l.recordRead(existingNode)
 	return
 }
 
-// Clear all values from the cache
-func (l *LRUCache[key, val]) Clear() {
+// Set the function called with each key and value leaving the cache,
+// and the reason, or nil for none. It is called without the cache lock
+// held, once the operation evicting the value has completed
+func (l *LRUCache[key, val]) OnEvict(f func(k key, v val, reason EvictReason)) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
+	l.onEvict = f
+}
+
+// Clear all values from the cache
+func (l *LRUCache[key, val]) Clear() {
+	l.lock()
+	evictions := []eviction[key, val]{}
+	if l.onEvict != nil {
+		for entry := range l.list.All() {
+			evictions = append(evictions, eviction[key, val]{entry.Key, entry.Value, EvictCleared})
+		}
+	}
 	l.list.Clear()
 	l.values.Clear()
+	l.weight = 0
+	onEvict := l.onEvict
+	l.mutex.Unlock()
+
+	notify(onEvict, evictions...)
+}
+
+// Remove the key from the cache
+// boolean ok indicates whether a value which had not expired was present
+func (l *LRUCache[key, val]) Remove(k key) (ok bool) {
+	l.lock()
+	existingNode, ok := l.values.Get(k)
+	if !ok {
+		l.mutex.Unlock()
+		return false
+	}
+	reason := EvictRemoved
+	if l.expired(existingNode.value) {
+		reason, ok = EvictExpired, false
+	}
+	l.unlink(k, existingNode)
+	onEvict := l.onEvict
+	l.mutex.Unlock()
+
+	notify(onEvict, eviction[key, val]{k, existingNode.value.Value, reason})
+	return ok
 }
 
 // Put the value into the map with the supplied key
 // replacing the value for the key as needed
-// Make the item most recent
+// Make the item most recent. It expires after the default TTL, if any
+// See PutWithTTL for eviction
 func (l *LRUCache[key, val]) Put(k key, v val) {
-	l.mutex.RLock()
-	defer l.mutex.RUnlock() // Map and list manage their own locks
+	l.PutWithTTL(k, v, l.defaultTTL)
+}
+
+// Put the value into the map with the supplied key
+// replacing the value for the key as needed
+// Make the item most recent. It expires once the TTL has elapsed,
+// or never if the TTL is not positive.
+// Least recent entries are evicted until the new value fits. A value
+// weighing more than the capacity is not cached, and is passed to the
+// OnEvict hook as evicted for capacity, along with any value it replaced
+func (l *LRUCache[key, val]) PutWithTTL(k key, v val, ttl time.Duration) {
+	weight := max(l.weigher(k, v), 0)
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = l.clock.Now().Add(ttl)
+	}
+
+	l.lock()
+	onEvict := l.onEvict
+	evictions := []eviction[key, val]{}
 
 	existingNode, present := l.values.Get(k)
 	if present {
-		existingNode.value.Value = v
-		l.list.ToFirst(existingNode)
+		old := eviction[key, val]{k, existingNode.value.Value, EvictReplaced}
+		if l.expired(existingNode.value) {
+			old.reason = EvictExpired
+		}
+		evictions = append(evictions, old)
+		l.unlink(k, existingNode)
+	}
+
+	if weight > int64(l.capacity) {
+		l.mutex.Unlock()
+		notify(onEvict, append(evictions, eviction[key, val]{k, v, EvictCapacity})...)
 		return
 	}
 
-	// if we are at capacity, evict the least recent
-	if l.values.Size() >= l.capacity {
-		last, ok := l.list.RemoveLast()
-		if ok {
-			l.values.Remove(last.Key)
+	// evict the least recent until the new value fits
+	for l.weight+weight > int64(l.capacity) {
+		last := l.list.Last()
+		l.unlink(last.value.Key, last)
+		reason := EvictCapacity
+		if l.expired(last.value) {
+			reason = EvictExpired
 		}
+		evictions = append(evictions, eviction[key, val]{last.value.Key, last.value.Value, reason})
 	}
 
 	// create the new node and make it the most recent
 	newNode := CacheEntry[key, val]{
-		Key:   k,
-		Value: v,
+		Key:     k,
+		Value:   v,
+		expires: expires,
+		weight:  weight,
 	}
 
 	l.list.AddFirst(&newNode)
 	l.values.Put(k, l.list.first)
+	l.weight += weight
+	l.mutex.Unlock()
+
+	notify(onEvict, evictions...)
+}
+
+// Return the total weight of the entries in the cache, including any
+// expired entries not yet removed. Without a Weigher each entry weighs 1
+func (l *LRUCache[key, val]) Weight() int64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.weight
+}
+
+// Remove every expired entry from the cache
+func (l *LRUCache[key, val]) RemoveExpired() {
+	l.lock()
+	evictions := []eviction[key, val]{}
+	for k, node := range l.values.All() {
+		if l.expired(node.value) {
+			l.unlink(k, node)
+			evictions = append(evictions, expiredEntry(node.value))
+		}
+	}
+	onEvict := l.onEvict
+	l.mutex.Unlock()
+
+	notify(onEvict, evictions...)
+}
+
+// Return the counts of hits and misses recorded by Get
+func (l *LRUCache[key, val]) Stats() CacheStats {
+	return CacheStats{Hits: l.hits.Load(), Misses: l.misses.Load()}
+}
+
+// Stop the janitor goroutine, if one was started
+// Safe to call more than once
+func (l *LRUCache[key, val]) Close() {
+	if l.done == nil {
+		return
+	}
+	l.close.Do(func() {
+		close(l.done)
+	})
+}
+
+// Used internally to return the value for the key if present and not
+// expired, without affecting recency or stats, or removing it if expired,
+// so no OnEvict hook is called
+func (l *LRUCache[key, val]) peek(k key) (value val, ok bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	node, ok := l.values.Get(k)
+	if !ok || l.expired(node.value) {
+		return value, false
+	}
+	return node.value.Value, true
+}
+
+// Used internally to check whether an entry has expired
+func (l *LRUCache[key, val]) expired(entry *CacheEntry[key, val]) bool {
+	return !entry.expires.IsZero() && !l.clock.Now().Before(entry.expires)
+}
+
+// Used internally to remove an expired entry found without the write
+// lock, if it is still mapped to the key and still expired once held
+func (l *LRUCache[key, val]) expire(k key, node *Node[*CacheEntry[key, val]]) {
+	l.lock()
+	current, ok := l.values.Get(k)
+	if !ok || current != node || !l.expired(node.value) {
+		l.mutex.Unlock()
+		return
+	}
+	l.unlink(k, node)
+	onEvict := l.onEvict
+	l.mutex.Unlock()
+
+	notify(onEvict, expiredEntry(node.value))
+}
+
+// Used internally to take the write lock, then move the entries of
+// buffered reads to the most recent position
+func (l *LRUCache[key, val]) lock() {
+	l.mutex.Lock()
+	l.drainReads()
+}
+
+// Used internally to record a read of the entry, to be applied to the
+// list by the next write. If the buffer is full it is drained now, if
+// the write lock is free, or else the read is dropped
+func (l *LRUCache[key, val]) recordRead(node *Node[*CacheEntry[key, val]]) {
+	select {
+	case l.reads <- node:
+		return
+	default:
+	}
+
+	if l.mutex.TryLock() {
+		l.drainReads()
+		l.toFirst(node)
+		l.mutex.Unlock()
+	}
+}
+
+// Used internally to apply every buffered read
+// Must be called with the write lock held
+func (l *LRUCache[key, val]) drainReads() {
+	for {
+		select {
+		case node := <-l.reads:
+			l.toFirst(node)
+		default:
+			return
+		}
+	}
+}
+
+// Used internally to move an entry to the most recent position,
+// unless it has left the cache since it was read
+// Must be called with the write lock held
+func (l *LRUCache[key, val]) toFirst(node *Node[*CacheEntry[key, val]]) {
+	if current, ok := l.values.Get(node.value.Key); ok && current == node {
+		l.list.ToFirst(node)
+	}
+}
+
+// Used internally to remove an entry from the list and map
+// Must be called with the write lock held
+func (l *LRUCache[key, val]) unlink(k key, node *Node[*CacheEntry[key, val]]) {
+	l.list.Unlink(node)
+	l.values.Remove(k)
+	l.weight -= node.value.weight
+}
+
+// A key and value which left the cache, held until the lock is released
+type eviction[key comparable, val any] struct {
+	key    key
+	value  val
+	reason EvictReason
+}
+
+// Used internally to record an expired entry as evicted
+func expiredEntry[key comparable, val any](entry *CacheEntry[key, val]) eviction[key, val] {
+	return eviction[key, val]{entry.Key, entry.Value, EvictExpired}
+}
+
+// Used internally to pass evicted values to the hook, if any
+// Must be called without the cache lock held
+func notify[key comparable, val any](onEvict func(k key, v val, reason EvictReason), evictions ...eviction[key, val]) {
+	if onEvict == nil {
+		return
+	}
+	for _, e := range evictions {
+		onEvict(e.key, e.value, e.reason)
+	}
+}
+
+// Used internally to remove expired entries at the interval until closed
+func (l *LRUCache[key, val]) janitor(interval time.Duration) {
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.clock.After(interval):
+			l.RemoveExpired()
+		}
+	}
 }
@@ -1,10 +1,14 @@
 package godatastructures
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestLRU(t *testing.T) {
@@ -124,3 +128,311 @@ func TestLRU(t *testing.T) {
 		}
 	}
 }
+
+func TestLRUExpiry(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test LRU entries expiring after their TTL")
+	{
+		clock := newFakeClock()
+		l, ok := NewLRUCache[string, int](10, WithDefaultTTL(time.Minute), WithClock(clock))
+		if !ok {
+			t.Fatalf("\t Create LRU with a default TTL failed")
+		}
+
+		l.Put("default", 1)
+		l.PutWithTTL("short", 2, time.Second)
+		l.PutWithTTL("forever", 3, 0)
+
+		clock.Advance(time.Second)
+		if l.Contains("short") {
+			t.Errorf("\t LRU should not contain an entry past its TTL")
+		}
+		if l.Len() != 2 {
+			t.Errorf("\t Contains should remove an expired entry, Len expected 2 : %v", l.Len())
+		}
+		if v, ok := l.Get("default"); !ok || v != 1 {
+			t.Errorf("\t Get before the default TTL expected 1 : %v %v", v, ok)
+		}
+
+		l.PutWithTTL("default", 4, 2*time.Minute) // replacing resets the TTL
+		clock.Advance(time.Minute)
+		if v, ok := l.Get("default"); !ok || v != 4 {
+			t.Errorf("\t Get after replacing with a longer TTL expected 4 : %v %v", v, ok)
+		}
+
+		clock.Advance(time.Minute)
+		if v, ok := l.Get("default"); ok {
+			t.Errorf("\t Get past the TTL returned a value: %v", v)
+		}
+		if v, ok := l.Get("forever"); !ok || v != 3 {
+			t.Errorf("\t Get on an entry without a TTL expected 3 : %v %v", v, ok)
+		}
+		if l.Len() != 1 {
+			t.Errorf("\t Len after expiry expected 1 : %v", l.Len())
+		}
+	}
+
+	t.Log("Given the need to test the LRU janitor removing expired entries")
+	{
+		clock := newFakeClock()
+		l, err := NewLRUCacheWithOptions[string, int](10, WithDefaultTTL(time.Second), WithClock(clock), WithJanitor(time.Minute))
+		if err != nil {
+			t.Fatalf("\t Create LRU with a janitor failed : %v", err)
+		}
+		defer l.Close()
+
+		l.Put("a", 1)
+		l.Put("b", 2)
+		<-clock.waiting // the janitor is waiting for its first interval
+		clock.Advance(time.Minute)
+		<-clock.waiting // the janitor has removed expired entries and is waiting again
+
+		if l.Len() != 0 {
+			t.Errorf("\t Janitor should remove expired entries, Len expected 0 : %v", l.Len())
+		}
+		l.Close()
+		l.Close() // closing twice must be safe
+	}
+
+	t.Log("Given the need to test invalid LRU options")
+	{
+		for i, option := range []LRUOption{WithDefaultTTL(0), WithClock(nil), WithJanitor(-time.Second)} {
+			if _, err := NewLRUCacheWithOptions[string, int](1, option); !errors.Is(err, ErrInvalidLRUOption) {
+				t.Errorf("\t%d\t Invalid option expected ErrInvalidLRUOption : %v", i, err)
+			}
+		}
+		if _, ok := NewLRUCache[string, int](0); ok {
+			t.Errorf("\t NewLRUCache should reject a capacity below 1")
+		}
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	t.Parallel()
+
+	type eviction struct {
+		key    string
+		value  int
+		reason EvictReason
+	}
+
+	t.Log("Given the need to test the LRU OnEvict hook")
+	{
+		clock := newFakeClock()
+		l, _ := NewLRUCache[string, int](2, WithClock(clock))
+
+		evictions := []eviction{}
+		l.OnEvict(func(k string, v int, reason EvictReason) {
+			evictions = append(evictions, eviction{k, v, reason})
+			l.Len() // the hook must be able to use the cache
+		})
+
+		l.Put("a", 1)
+		l.Put("b", 2)
+		l.Put("a", 10) // replaced
+		l.Put("c", 3)  // b evicted for capacity
+		l.Remove("a")  // removed
+		l.PutWithTTL("d", 4, time.Second)
+		clock.Advance(time.Second)
+		l.Get("d") // expired
+		l.Put("e", 5)
+		l.Clear() // e and c cleared, most recent first
+		if l.Remove("missing") {
+			t.Errorf("\t Remove of a missing key should return false")
+		}
+
+		expected := []eviction{
+			{"a", 1, EvictReplaced},
+			{"b", 2, EvictCapacity},
+			{"a", 10, EvictRemoved},
+			{"d", 4, EvictExpired},
+			{"e", 5, EvictCleared},
+			{"c", 3, EvictCleared},
+		}
+		if !slices.Equal(evictions, expected) {
+			t.Errorf("\t OnEvict expected %v : %v", expected, evictions)
+		}
+
+		evictions = evictions[:0]
+		l.PutWithTTL("f", 6, time.Second)
+		l.PutWithTTL("g", 7, time.Minute)
+		clock.Advance(time.Second)
+		l.RemoveExpired()
+		if !slices.Equal(evictions, []eviction{{"f", 6, EvictExpired}}) {
+			t.Errorf("\t RemoveExpired should report expiry : %v", evictions)
+		}
+
+		evictions = evictions[:0]
+		l.OnEvict(nil)
+		l.Clear()
+		if len(evictions) != 0 {
+			t.Errorf("\t OnEvict cleared should not be called : %v", evictions)
+		}
+
+		if EvictExpired.String() != "expired" || EvictReason(99).String() != "EvictReason(99)" {
+			t.Errorf("\t EvictReason String unexpected : %v %v", EvictExpired, EvictReason(99))
+		}
+	}
+}
+
+func TestLRUWeigher(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test LRU capacity as a total weight")
+	{
+		weigher := Weigher[string, []byte](func(k string, v []byte) int64 { return int64(len(v)) })
+		l, err := NewLRUCacheWithOptions[string, []byte](100, WithWeigher(weigher))
+		if err != nil {
+			t.Fatalf("\t Create LRU with a weigher failed : %v", err)
+		}
+
+		evicted := []string{}
+		l.OnEvict(func(k string, v []byte, reason EvictReason) {
+			evicted = append(evicted, k+":"+reason.String())
+		})
+
+		l.Put("a", make([]byte, 40))
+		l.Put("b", make([]byte, 30))
+		l.Put("c", make([]byte, 20))
+		l.Get("a")
+		if l.Weight() != 90 || l.Len() != 3 {
+			t.Errorf("\t Weight expected 90 with 3 entries : %v %v", l.Weight(), l.Len())
+		}
+
+		l.Put("d", make([]byte, 50)) // evicts b then c, the least recent
+		if !slices.Equal(evicted, []string{"b:capacity", "c:capacity"}) {
+			t.Errorf("\t Put should evict until the entry fits : %v", evicted)
+		}
+		if l.Weight() != 90 || !l.Contains("a") || !l.Contains("d") {
+			t.Errorf("\t Weight after eviction expected 90 : %v", l.Weight())
+		}
+
+		evicted = evicted[:0]
+		l.Put("a", make([]byte, 10)) // replacing adjusts the weight
+		if l.Weight() != 60 || !slices.Equal(evicted, []string{"a:replaced"}) {
+			t.Errorf("\t Replacing should adjust the weight to 60 : %v %v", l.Weight(), evicted)
+		}
+
+		evicted = evicted[:0]
+		l.Put("huge", make([]byte, 101))
+		if l.Contains("huge") || l.Weight() != 60 {
+			t.Errorf("\t An entry heavier than the capacity should be rejected : %v", l.Weight())
+		}
+		if !slices.Equal(evicted, []string{"huge:capacity"}) {
+			t.Errorf("\t A rejected entry should be passed to OnEvict : %v", evicted)
+		}
+
+		l.Remove("a")
+		if l.Weight() != 50 {
+			t.Errorf("\t Weight after Remove expected 50 : %v", l.Weight())
+		}
+		l.Clear()
+		if l.Weight() != 0 {
+			t.Errorf("\t Weight after Clear expected 0 : %v", l.Weight())
+		}
+	}
+
+	t.Log("Given the need to test a weigher of the wrong types")
+	{
+		weigher := Weigher[int, []byte](func(k int, v []byte) int64 { return 1 })
+		if _, err := NewLRUCacheWithOptions[string, []byte](10, WithWeigher(weigher)); !errors.Is(err, ErrInvalidLRUOption) {
+			t.Errorf("\t Mismatched weigher expected ErrInvalidLRUOption : %v", err)
+		}
+	}
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test LRU consistency under concurrent use")
+	{
+		const capacity, keys, workers, ops = 50, 200, 8, 5000
+
+		l, ok := NewLRUCache[int, int](capacity)
+		if !ok {
+			t.Fatalf("\t Create LRU with capacity %d failed", capacity)
+		}
+
+		// checks, under the write lock, that the list and map agree
+		check := func() error {
+			l.lock()
+			defer l.mutex.Unlock()
+
+			if l.values.Size() > capacity {
+				return fmt.Errorf("%d entries exceeds capacity %d", l.values.Size(), capacity)
+			}
+			if l.list.Size() != l.values.Size() {
+				return fmt.Errorf("list size %d != map size %d", l.list.Size(), l.values.Size())
+			}
+			count := 0
+			for n := l.list.First(); n != nil; n = n.Next() {
+				count++
+				if node, ok := l.values.Get(n.value.Key); !ok || node != n {
+					return fmt.Errorf("list node for key %d is not in the map", n.value.Key)
+				}
+			}
+			if count != l.list.Size() {
+				return fmt.Errorf("walked %d nodes, list size %d", count, l.list.Size())
+			}
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		for w := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r := rand.New(rand.NewSource(int64(w)))
+				for range ops {
+					k := r.Intn(keys)
+					switch r.Intn(10) {
+					case 0:
+						l.Remove(k)
+					case 1, 2, 3:
+						l.Put(k, k)
+					default:
+						if v, ok := l.Get(k); ok && v != k {
+							t.Errorf("\t Get(%d) returned %d", k, v)
+						}
+					}
+					if n := l.Len(); n > l.Cap() {
+						t.Errorf("\t Len %d exceeds Cap %d", n, l.Cap())
+					}
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		// the first inconsistency is kept until the workers finish, as
+		// failing the test while they run would end it under them
+		var inconsistent error
+		for running := true; running; {
+			select {
+			case <-done:
+				running = false
+			default:
+			}
+			if err := check(); err != nil && inconsistent == nil {
+				inconsistent = err
+			}
+		}
+		if inconsistent != nil {
+			t.Fatalf("\t Inconsistent LRU : %v", inconsistent)
+		}
+
+		// a key read after the others were put is kept
+		for k := range capacity {
+			l.Put(k, k)
+		}
+		l.Get(0)
+		l.Put(capacity, capacity)
+		if !l.Contains(0) || l.Contains(1) {
+			t.Errorf("\t Buffered read should keep key 0 and evict key 1")
+		}
+	}
+}
@@ -1,51 +1,234 @@
 package godatastructures
 
 import (
+	"errors"
 	"fmt"
 	"hash/maphash"
-	"sync"
-	"sync/atomic"
-	"unsafe"
-
-	"golang.org/x/sync/semaphore"
+	"iter"
+	"math"
 )
 
 // The seed for the hashing function
 var seed = maphash.MakeSeed()
 
-// load factor is a limit to the ratio between size and capacity
-// a resize is triggered (in background routine) if the load factor is exceeded
-// to prevent the average list length growing too large
-const loadFactor int = 5
+// Function used to hash keys to a uint64. Keys that are equal
+// must hash to the same value
+type Hasher[key comparable] func(k key) uint64
+
+// Storage strategy used behind a Map
+type MapBackend int
+
+const (
+	// Buckets of linked lists, with incremental growth (the default)
+	ChainedBackend MapBackend = iota
+	// Swiss table style open addressing, with entries stored inline
+	// in groups of slots probed using control bytes
+	OpenAddressingBackend
+)
+
+// How the entries of a chained map are migrated when it is resized
+type ResizeMode int
+
+const (
+	// Operations following a resize each migrate a few buckets (the default)
+	IncrementalResize ResizeMode = iota
+	// A background goroutine migrates every bucket, alongside operations
+	BackgroundResize
+	// The operation triggering a resize migrates every bucket before returning
+	SynchronousResize
+)
+
+// Function used to choose the capacity to grow to from the current
+// capacity. Results not larger than the current capacity are ignored
+type GrowthPolicy func(capacity int) int
+
+// Returned by NewMapWithOptions when an option has an invalid value
+var ErrInvalidMapOption = errors.New("map: invalid option")
+
+// Option used to configure a Map at construction
+type MapOption func(*mapConfig) error
+
+// Settings collected from the options supplied to NewMap
+type mapConfig struct {
+	backend     MapBackend
+	hasher      any     // Hasher[key] for the key type of the map
+	loadFactor  float64 // zero for the backend default
+	minCapacity int
+	growth      GrowthPolicy // nil for the backend default
+	resizeMode  ResizeMode
+}
+
+// Use the supplied function to hash keys instead of the default.
+// The key type of the hasher must match the key type of the map
+func WithHasher[key comparable](h Hasher[key]) MapOption {
+	return func(c *mapConfig) error {
+		if h == nil {
+			return fmt.Errorf("%w: hasher must not be nil", ErrInvalidMapOption)
+		}
+		c.hasher = h
+		return nil
+	}
+}
+
+// Use the supplied storage strategy for the map
+func WithBackend(b MapBackend) MapOption {
+	return func(c *mapConfig) error {
+		if b != ChainedBackend && b != OpenAddressingBackend {
+			return fmt.Errorf("%w: unknown backend %d", ErrInvalidMapOption, b)
+		}
+		c.backend = b
+		return nil
+	}
+}
+
+// Set the ratio of entries to capacity at which the map grows.
+// For the chained backend this is the average bucket length, and must
+// be positive (default 5). For open addressing it is the fraction of
+// slots filled, and must be in the range (0, 1) (default 7/8)
+func WithLoadFactor(f float64) MapOption {
+	return func(c *mapConfig) error {
+		if !(f > 0) || math.IsInf(f, 1) {
+			return fmt.Errorf("%w: load factor %v must be positive", ErrInvalidMapOption, f)
+		}
+		c.loadFactor = f
+		return nil
+	}
+}
+
+// Set the initial capacity of the map, which it never shrinks below.
+// Must be at least 1
+func WithMinCapacity(capacity int) MapOption {
+	return func(c *mapConfig) error {
+		if capacity < 1 {
+			return fmt.Errorf("%w: minimum capacity %d must be at least 1", ErrInvalidMapOption, capacity)
+		}
+		c.minCapacity = capacity
+		return nil
+	}
+}
+
+// Use the supplied function to choose the capacity to grow to.
+// Only supported by the chained backend
+func WithGrowthPolicy(f GrowthPolicy) MapOption {
+	return func(c *mapConfig) error {
+		if f == nil {
+			return fmt.Errorf("%w: growth policy must not be nil", ErrInvalidMapOption)
+		}
+		c.growth = f
+		return nil
+	}
+}
+
+// Set how entries are migrated when the map is resized.
+// Only supported by the chained backend; open addressing
+// always rehashes synchronously
+func WithResizeMode(mode ResizeMode) MapOption {
+	return func(c *mapConfig) error {
+		if mode < IncrementalResize || mode > SynchronousResize {
+			return fmt.Errorf("%w: unknown resize mode %d", ErrInvalidMapOption, mode)
+		}
+		c.resizeMode = mode
+		return nil
+	}
+}
+
+// Storage behind a Map. Implementations are responsible
+// for their own locking and growth
+type mapStore[key comparable, val comparable] interface {
+	get(k key) (v val, ok bool)
+	put(k key, v val)
+	remove(k key) (ok bool)
+	// Atomically replace the mapping for k with the result of f, which
+	// returns the new value and whether to keep (or add) the mapping
+	compute(k key, f func(old val, present bool) (v val, keep bool)) (v val, ok bool)
+	clear()
+	len() int
+	// Call yield with copies of the entries until it returns false.
+	// No locks may be held while yield is running
+	entries(yield func(MapEntry[key, val]) bool)
+	// Return a copy of every entry, taken at a single point in time
+	snapshot() []MapEntry[key, val]
+	// Report whether each of the keys is present, all at a single point in time
+	containsEach(keys []key) []bool
+}
 
 // Generic hashmap with mutex and growth behaviour
 type Map[key comparable, val comparable] struct {
-	buckets  []LList[*MapEntry[key, val]]
-	capacity int
-	mutex    sync.RWMutex
-	resize   *semaphore.Weighted // Indicates resize in progress
-	size     int
+	store mapStore[key, val]
 }
 
 // constructor
-func NewMap[key comparable, val comparable](capacity int) *Map[key, val] {
+// For the chained backend capacity is the initial number of buckets,
+// for open addressing it is the number of entries to allocate room for.
+// A capacity below 1 is treated as 1. Panics if an option is invalid,
+// use NewMapWithOptions to handle invalid options as errors
+func NewMap[key comparable, val comparable](capacity int, options ...MapOption) *Map[key, val] {
+	options = append([]MapOption{WithMinCapacity(max(1, capacity))}, options...)
+
+	m, err := NewMapWithOptions[key, val](options...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// constructor
+// Returns an error wrapping ErrInvalidMapOption if any option is invalid
+// or is not supported by the chosen backend. Without WithMinCapacity the
+// map starts with a capacity of 1
+func NewMapWithOptions[key comparable, val comparable](options ...MapOption) (*Map[key, val], error) {
+
+	config := mapConfig{
+		minCapacity: 1,
+	}
+	for _, option := range options {
+		if err := option(&config); err != nil {
+			return nil, err
+		}
+	}
 
-	m := Map[key, val]{
-		buckets:  make([]LList[*MapEntry[key, val]], capacity),
-		capacity: capacity,
-		resize:   semaphore.NewWeighted(1),
+	hasher := hash[key]
+	if config.hasher != nil {
+		h, ok := config.hasher.(Hasher[key])
+		if !ok {
+			return nil, fmt.Errorf("%w: hasher %T does not match key type of Map[%T]", ErrInvalidMapOption, config.hasher, *new(key))
+		}
+		hasher = h
 	}
 
-	return &m
+	m := Map[key, val]{}
+
+	switch config.backend {
+	case ChainedBackend:
+		if config.loadFactor == 0 {
+			config.loadFactor = loadFactor
+		}
+		if config.growth == nil {
+			config.growth = newCapacity
+		}
+		m.store = newChainedMap[key, val](config, hasher)
+	case OpenAddressingBackend:
+		if config.loadFactor == 0 {
+			config.loadFactor = swissLoadFactor
+		}
+		if config.loadFactor >= 1 {
+			return nil, fmt.Errorf("%w: open addressing load factor %v must be less than 1", ErrInvalidMapOption, config.loadFactor)
+		}
+		if config.resizeMode != IncrementalResize {
+			return nil, fmt.Errorf("%w: resize mode is not supported by open addressing", ErrInvalidMapOption)
+		}
+		if config.growth != nil {
+			return nil, fmt.Errorf("%w: growth policy is not supported by open addressing", ErrInvalidMapOption)
+		}
+		m.store = newSwissMap[key, val](config, hasher)
+	}
+
+	return &m, nil
 }
 
 // Returns the number of key-value mappings in this map.
 func (m *Map[key, val]) Size() int {
-	if unsafe.Sizeof(m.size) == 8 {
-		return int(atomic.LoadInt64((*int64)(unsafe.Pointer(&m.size))))
-	} else {
-		return int(atomic.LoadInt32((*int32)(unsafe.Pointer(&m.size))))
-	}
+	return m.store.len()
 }
 
 // Structure to store key-value mappings in the map
@@ -54,62 +237,31 @@ type MapEntry[key comparable, val any] struct {
 	value val
 }
 
+// constructor
+func NewMapEntry[key comparable, val any](k key, v val) MapEntry[key, val] {
+	return MapEntry[key, val]{key: k, value: v}
+}
+
+// Get the key of the entry
+func (e MapEntry[key, val]) Key() key {
+	return e.key
+}
+
+// Get the value of the entry
+func (e MapEntry[key, val]) Value() val {
+	return e.value
+}
+
 // Returns the value to which the specified key is mapped,
 // or the zero value if not present. Boolean ok indicates
 // whether the value was present
 func (m *Map[key, val]) Get(k key) (value val, ok bool) {
-
-	// read lock on struct
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	idx := hash(k, m.capacity)
-
-	m.buckets[idx].mutex.RLock()
-	defer m.buckets[idx].mutex.RUnlock()
-
-	entry, ok := m.buckets[idx].FindFirstFunc(func(v *MapEntry[key, val]) bool {
-		return v.key == k
-	})
-	if ok {
-		value = entry.value.value
-		return
-	}
-	ok = false
-	return
-
+	return m.store.get(k)
 }
 
 // Associates the specified value with the specified key in this map.
 func (m *Map[key, val]) Put(k key, v val) {
-
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	if (m.Size() / m.capacity) > loadFactor {
-		if m.resize.TryAcquire(1) {
-			go m.grow()
-		}
-	}
-
-	idx := hash(k, m.capacity)
-
-	entry, ok := m.buckets[idx].FindFirstFunc(func(v *MapEntry[key, val]) bool {
-		return v.key == k
-	})
-	if ok {
-		entry.value.value = v
-		return
-	} else {
-		entry := MapEntry[key, val]{key: k, value: v}
-		m.buckets[idx].AddFirst(&entry)
-		if unsafe.Sizeof(m.size) == 8 {
-			atomic.AddInt64((*int64)(unsafe.Pointer(&m.size)), 1)
-		} else {
-			atomic.AddInt32((*int32)(unsafe.Pointer(&m.size)), 1)
-		}
-	}
-
+	m.store.put(k, v)
 }
 
 // Stores all the provided key/value pairs in the map, replacing any
@@ -122,156 +274,174 @@ func (m *Map[key, val]) PutAll(entries []MapEntry[key, val]) {
 
 // Removes the mapping for the specified key from this map if present.
 func (m *Map[key, val]) Remove(k key) (ok bool) {
+	return m.store.remove(k)
+}
 
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+// Associates the value with the key only if the key is not already
+// mapped. Returns the value now mapped to the key, and true if it was
+// already present rather than stored
+func (m *Map[key, val]) PutIfAbsent(k key, v val) (value val, present bool) {
+	m.store.compute(k, func(old val, ok bool) (val, bool) {
+		if ok {
+			value, present = old, true
+			return old, true
+		}
+		value = v
+		return v, true
+	})
+	return
+}
 
-	idx := hash(k, m.capacity)
+// Returns the value mapped to the key, computing and storing it with f
+// if the key is not present. f is called at most once per absent key
+// while the key's lock is held, so must not use the map.
+// Boolean present is false if the value was computed
+func (m *Map[key, val]) GetOrCompute(k key, f func() val) (value val, present bool) {
+	if value, present = m.store.get(k); present {
+		return
+	}
+	m.store.compute(k, func(old val, ok bool) (val, bool) {
+		if ok {
+			value, present = old, true
+			return old, true
+		}
+		value = f()
+		return value, true
+	})
+	return
+}
 
-	entry, ok := m.buckets[idx].FindFirstFunc(func(v *MapEntry[key, val]) bool {
-		return v.key == k
+// Atomically replaces the mapping for the key with the result of f.
+// f receives the current value and whether it was present, and returns
+// the new value and whether to keep it; returning false removes the
+// mapping (or does not add it). f is called while the key's lock is
+// held, so must not use the map. Returns the new value and whether
+// the key is now mapped
+func (m *Map[key, val]) Compute(k key, f func(old val, present bool) (val, bool)) (value val, ok bool) {
+	return m.store.compute(k, f)
+}
+
+// Associates the value with the key if it is not present, otherwise
+// replaces the existing value with the result of f(existing, v).
+// f is called while the key's lock is held, so must not use the map.
+// Returns the value now mapped to the key
+func (m *Map[key, val]) Merge(k key, v val, f func(old val, v val) val) val {
+	value, _ := m.store.compute(k, func(old val, present bool) (val, bool) {
+		if present {
+			return f(old, v), true
+		}
+		return v, true
 	})
-	if ok {
-		m.buckets[idx].Unlink(entry)
-		if unsafe.Sizeof(m.size) == 8 {
-			atomic.AddInt64((*int64)(unsafe.Pointer(&m.size)), -1)
-		} else {
-			atomic.AddInt32((*int32)(unsafe.Pointer(&m.size)), -1)
+	return value
+}
+
+// Replaces the value for the key with new only if it is currently
+// mapped to old. Returns true if the value was swapped
+func (m *Map[key, val]) CompareAndSwap(k key, old val, new val) (swapped bool) {
+	m.store.compute(k, func(current val, present bool) (val, bool) {
+		if present && current == old {
+			swapped = true
+			return new, true
 		}
-		return
-	}
+		return current, present
+	})
+	return
+}
 
+// Removes the mapping for the key only if it is currently mapped
+// to old. Returns true if the mapping was removed
+func (m *Map[key, val]) CompareAndDelete(k key, old val) (deleted bool) {
+	m.store.compute(k, func(current val, present bool) (val, bool) {
+		if present && current == old {
+			deleted = true
+			return current, false
+		}
+		return current, present
+	})
 	return
 }
 
 // Removes all of the mappings from this map.
 func (m *Map[key, val]) Clear() {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	m.buckets = make([]LList[*MapEntry[key, val]], m.capacity)
-	if unsafe.Sizeof(m.size) == 8 {
-		atomic.StoreInt64((*int64)(unsafe.Pointer(&m.size)), 0)
-	} else {
-		atomic.StoreInt32((*int32)(unsafe.Pointer(&m.size)), 0)
-	}
-
-	m.size = 0
-
+	m.store.clear()
 }
 
 // Returns true if this map contains a mapping for the specified key
 func (m *Map[key, val]) ContainsKey(k key) (ok bool) {
-
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	idx := hash(k, m.capacity)
-
-	_, ok = m.buckets[idx].FindFirstFunc(func(v *MapEntry[key, val]) bool {
-		return v.key == k
-	})
+	_, ok = m.store.get(k)
 	return
-
 }
 
 // Returns true if this map maps one or more keys to the specified value
 func (m *Map[key, val]) ContainsValue(v val) bool {
-
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	for b := range m.buckets {
-		_, ok := m.buckets[b].FindFirstFunc(
-			func(e *MapEntry[key, val]) bool {
-				return e.value == v
-			})
-		if ok {
+	for value := range m.Values() {
+		if value == v {
 			return true
 		}
 	}
 	return false
 }
 
-// Returns a set of the keys in the map
+// Returns a set of the keys present in the map at a single point in time
 func (m *Map[key, val]) KeySet() *Set[key] {
 	s := NewSet[key]()
-
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	for b := range m.buckets {
-		m.buckets[b].Do(
-			func(e *MapEntry[key, val]) {
-				s.Add(e.key)
-			})
-	}
-
+	s.AddSlice(m.keySnapshot())
 	return s
 }
 
-// Returns a slice of the values in the map
-func (m *Map[key, val]) Values() (values []val) {
-
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-	for b := range m.buckets {
-		m.buckets[b].Do(
-			func(e *MapEntry[key, val]) {
-				values = append(values, e.value)
-			})
+// Returns an iterator over the key-value mappings in the map.
+// Iteration is weakly consistent: entries are copied under lock a
+// bucket or group at a time before they are yielded, so no locks are
+// held by the loop body, which may safely modify the map. Mappings
+// present throughout the iteration are yielded exactly once, changes
+// made during iteration may or may not be observed
+func (m *Map[key, val]) All() iter.Seq2[key, val] {
+	return func(yield func(key, val) bool) {
+		m.store.entries(func(e MapEntry[key, val]) bool {
+			return yield(e.key, e.value)
+		})
 	}
-	return
 }
 
-// Used internally to grow the backing array
-func (m *Map[key, val]) grow() {
-	defer m.resize.Release(1) // release the semaphor when done, to allow grow to run again
-
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	if (m.size / m.capacity) < loadFactor {
-		return
+// Returns an iterator over the keys in the map, with the
+// same consistency guarantees as All
+func (m *Map[key, val]) Keys() iter.Seq[key] {
+	return func(yield func(key) bool) {
+		m.store.entries(func(e MapEntry[key, val]) bool {
+			return yield(e.key)
+		})
 	}
+}
 
-	newCapacity := newCapacity(m.capacity)
-
-	newBuckets := make([]LList[*MapEntry[key, val]], newCapacity)
-
-	for b := range m.buckets {
-		m.buckets[b].Do(func(e *MapEntry[key, val]) {
-			idx := hash(e.key, newCapacity)
-			newBuckets[idx].AddFirst(e)
+// Returns an iterator over the values in the map, with the
+// same consistency guarantees as All
+func (m *Map[key, val]) Values() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		m.store.entries(func(e MapEntry[key, val]) bool {
+			return yield(e.value)
 		})
 	}
-	m.buckets = newBuckets
-	m.capacity = newCapacity
-
 }
 
-// Used internally to hash a key to a int index
-func hash[key comparable](k key, capacity int) int {
-	var h maphash.Hash
-	h.SetSeed(seed)
-	h.Write([]byte(fmt.Sprintf("%v", k)))
-	return int(h.Sum64() % uint64(capacity))
+// Returns an iterator over copies of the entries in the map, with the
+// same consistency guarantees as All. The entries may be passed to PutAll
+func (m *Map[key, val]) Entries() iter.Seq[MapEntry[key, val]] {
+	return m.store.entries
 }
 
-// Used internally to decide (smoothed) growth rate. Figures based on
-// https://go.googlesource.com/go/+/2dda92ff6f9f07eeb110ecbf0fc2d7a0ddd27f9d
-func newCapacity(currentCapacity int) int {
-
-	if currentCapacity < 256 {
-		return 2 * currentCapacity
-	}
-	if currentCapacity < 512 {
-		return int(1.63 * float64(currentCapacity))
+// Used internally to copy the keys present at a single point in time
+func (m *Map[key, val]) keySnapshot() []key {
+	entries := m.store.snapshot()
+	keys := make([]key, len(entries))
+	for i := range entries {
+		keys[i] = entries[i].key
 	}
-	if currentCapacity < 1024 {
-		return int(1.44 * float64(currentCapacity))
-	}
-	if currentCapacity < 2048 {
-		return int(1.35 * float64(currentCapacity))
-	}
-	return int(1.30 * float64(currentCapacity))
+	return keys
+}
+
+// Used internally as the default Hasher. Hashes the key by its type
+// and memory representation, without allocating, so keys of different
+// dynamic types in an interface keyed map do not collide
+func hash[key comparable](k key) uint64 {
+	return maphash.Comparable(seed, k)
 }
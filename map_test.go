@@ -1,11 +1,25 @@
 package godatastructures
 
 import (
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"slices"
 	"strconv"
 	"sync"
 	"testing"
 )
 
+// Backends to run the Map tests against
+var mapBackends = []struct {
+	name    string
+	backend MapBackend
+}{
+	{name: "chained", backend: ChainedBackend},
+	{name: "open addressing", backend: OpenAddressingBackend},
+}
+
 func TestMap(t *testing.T) {
 
 	t.Parallel()
@@ -35,14 +49,15 @@ func TestMap(t *testing.T) {
 	}
 
 	t.Log("Given the need to test Map behaviour on sample data")
-	{
+	for _, backend := range mapBackends {
+		t.Logf("Using the %v backend", backend.name)
 		for i, test := range tests {
 			t.Logf("\tTest: %d\t When testing source data %v ", i, test.source)
 			{
 
 				t.Logf("\t%d\t Testing empty map behaviour", i)
 
-				m := NewMap[string, int](10)
+				m := NewMap[string, int](10, WithBackend(backend.backend))
 
 				v, ok := m.Get("non_existent_1")
 				if ok {
@@ -61,12 +76,12 @@ func TestMap(t *testing.T) {
 				if m.ContainsValue(-1000) {
 					t.Errorf("\t%d\t ContainsValue on empty map returned true", i)
 				}
-				if len(m.Values()) != 0 {
-					t.Errorf("\t%d\t Values on empty map were not empty: %v", i, m.Values())
+				if len(slices.Collect(m.Values())) != 0 {
+					t.Errorf("\t%d\t Values on empty map were not empty: %v", i, slices.Collect(m.Values()))
 				}
 
 				if m.KeySet().Size() != 0 {
-					t.Errorf("\t%d\t Values on empty map were not empty: %v", i, m.Values())
+					t.Errorf("\t%d\t Values on empty map were not empty: %v", i, slices.Collect(m.Values()))
 				}
 
 				if m.Size() != 0 {
@@ -78,7 +93,7 @@ func TestMap(t *testing.T) {
 				m.PutAll(test.source)
 
 				if m.Size() != len(test.source) {
-					t.Errorf("\t%d\t PutAll on sample data should be correct length %d : %d", i, len(test.source), m.size)
+					t.Errorf("\t%d\t PutAll on sample data should be correct length %d : %d", i, len(test.source), m.Size())
 				}
 
 				for v := range test.source {
@@ -104,13 +119,13 @@ func TestMap(t *testing.T) {
 				}
 
 				{
-					vs := m.Values()
+					vs := slices.Collect(m.Values())
 					if len(vs) != len(test.source) {
 						t.Errorf("\t%d\t Value set was unexpected size %d : %d", i, len(vs), len(test.source))
 					}
 
 					// n^2 is ok for small test sizes
-					values := m.Values()
+					values := slices.Collect(m.Values())
 					for _, sourceVal := range test.source {
 						found := false
 						for _, val := range values {
@@ -169,7 +184,7 @@ func TestMap(t *testing.T) {
 					}
 
 					// n^2 is ok for small test sizes
-					values := m.Values()
+					values := slices.Collect(m.Values())
 					for _, sourceVal := range test.source {
 						found := false
 						for _, val := range values {
@@ -193,11 +208,13 @@ func TestMapConcurrent(t *testing.T) {
 	t.Parallel()
 
 	t.Log("Given the need to test Map behaviour concurrently")
-	{
+	for _, backend := range mapBackends {
+		t.Logf("Using the %v backend", backend.name)
+
 		numAttempts := 500
 
 		t.Logf("Testing Put with %v concurrent", numAttempts)
-		m := NewMap[string, int](10)
+		m := NewMap[string, int](10, WithBackend(backend.backend))
 
 		wg := sync.WaitGroup{}
 		wg.Add(numAttempts)
@@ -212,7 +229,7 @@ func TestMapConcurrent(t *testing.T) {
 
 		wg.Wait()
 
-		values := m.Values()
+		values := slices.Collect(m.Values())
 		if len(values) != numAttempts {
 			t.Errorf("\t Values did not contain correct number of elements %v : %v :  %v", numAttempts, len(values), (values))
 		}
@@ -234,25 +251,35 @@ func TestMapConcurrent(t *testing.T) {
 		}
 
 		wg.Wait()
-		values = m.Values()
+		values = slices.Collect(m.Values())
 		t.Logf("Size: %v", m.Size())
 
 		if len(values) != numAttempts {
 			t.Errorf("\t After concurrent add and remove, values did not contain number of elements %v : %v - %v", numAttempts, len(values), values)
 		}
 
+		chained, ok := m.store.(*chainedMap[string, int])
+		if !ok {
+			continue
+		}
+
 		t.Log("Testing the internal lists are consistent")
 
+		// iterating Values above completes any migration in progress
+		if chained.old != nil {
+			t.Errorf("\t Map should not be migrating after iteration")
+		}
+
 		totalItems := 0
-		for b := range m.buckets {
+		for b := range chained.table.buckets {
 			itemsInBucket := 0
-			m.buckets[b].Do(func(e *MapEntry[string, int]) {
+			chained.table.buckets[b].Do(func(e MapEntry[string, int]) {
 				itemsInBucket++
 			})
 			totalItems += itemsInBucket
 
-			if itemsInBucket != m.buckets[b].size {
-				t.Errorf("\t Bucket %v did not contain the expected number of items : %v :  %v", b, m.buckets[b].size, itemsInBucket)
+			if itemsInBucket != chained.table.buckets[b].size {
+				t.Errorf("\t Bucket %v did not contain the expected number of items : %v :  %v", b, chained.table.buckets[b].size, itemsInBucket)
 			}
 		}
 		if totalItems != int(m.Size()) {
@@ -262,3 +289,501 @@ func TestMapConcurrent(t *testing.T) {
 	}
 
 }
+
+func TestMapIterators(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test Map iterators")
+	for _, backend := range mapBackends {
+		t.Logf("Using the %v backend", backend.name)
+
+		m := NewMap[string, int](4, WithBackend(backend.backend))
+		for i := range 100 {
+			m.Put(strconv.Itoa(i), i)
+		}
+
+		t.Log("Testing All yields every mapping once")
+		{
+			seen := map[string]int{}
+			for k, v := range m.All() {
+				seen[k] += 1
+				if got, _ := m.Get(k); got != v {
+					t.Errorf("\t All yielded wrong value for %v : %v : %v", k, got, v)
+				}
+			}
+			if len(seen) != m.Size() {
+				t.Errorf("\t All yielded unexpected number of keys %v : %v", m.Size(), len(seen))
+			}
+			for k, n := range seen {
+				if n != 1 {
+					t.Errorf("\t All yielded key %v %v times", k, n)
+				}
+			}
+		}
+
+		t.Log("Testing Keys, Values and Entries agree")
+		{
+			keys := slices.Collect(m.Keys())
+			values := slices.Collect(m.Values())
+			entries := slices.Collect(m.Entries())
+			if len(keys) != m.Size() || len(values) != m.Size() || len(entries) != m.Size() {
+				t.Errorf("\t Iterators yielded unexpected lengths %v : %v : %v : %v", m.Size(), len(keys), len(values), len(entries))
+			}
+			for _, e := range entries {
+				if strconv.Itoa(e.Value()) != e.Key() {
+					t.Errorf("\t Entry has unexpected value %v : %v", e.Key(), e.Value())
+				}
+			}
+
+			copied := NewMap[string, int](1)
+			copied.PutAll(entries)
+			if copied.Size() != m.Size() {
+				t.Errorf("\t PutAll of Entries should copy the map %v : %v", m.Size(), copied.Size())
+			}
+		}
+
+		t.Log("Testing modifying the map during iteration")
+		{
+			for k := range m.Keys() {
+				m.Remove(k)
+			}
+			if m.Size() != 0 {
+				t.Errorf("\t Removing every key during iteration should empty the map : %v", m.Size())
+			}
+		}
+	}
+}
+
+func TestMapHashing(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test Map key hashing")
+	{
+		t.Log("Testing keys with the same formatted value do not collide")
+		{
+			m := NewMap[any, string](1)
+			m.Put(1, "int")
+			m.Put("1", "string")
+			m.Put(int64(1), "int64")
+
+			if m.Size() != 3 {
+				t.Errorf("\t Keys of different types should be distinct : %v", m.Size())
+			}
+			if v, _ := m.Get(1); v != "int" {
+				t.Errorf("\t Get on int key returned unexpected value %v", v)
+			}
+			if v, _ := m.Get("1"); v != "string" {
+				t.Errorf("\t Get on string key returned unexpected value %v", v)
+			}
+		}
+
+		t.Log("Testing pointer keys hash by identity")
+		{
+			a, b := new(int), new(int)
+			m := NewMap[*int, int](1)
+			m.Put(a, 1)
+			m.Put(b, 2)
+			if m.Size() != 2 {
+				t.Errorf("\t Distinct pointers should be distinct keys : %v", m.Size())
+			}
+		}
+
+		t.Log("Testing a user supplied hasher")
+		{
+			calls := 0
+			m := NewMap[int, int](10, WithHasher(func(k int) uint64 {
+				calls++
+				return uint64(k)
+			}))
+			for i := range 20 {
+				m.Put(i, i)
+			}
+			for i := range 20 {
+				if v, ok := m.Get(i); !ok || v != i {
+					t.Errorf("\t Get with custom hasher expected %v : %v", i, v)
+				}
+			}
+			if calls == 0 {
+				t.Errorf("\t Custom hasher was not used")
+			}
+		}
+
+		t.Log("Testing a hasher for the wrong key type panics")
+		{
+			defer func() {
+				if recover() == nil {
+					t.Errorf("\t NewMap with mismatched hasher should panic")
+				}
+			}()
+			NewMap[string, int](10, WithHasher(func(k int) uint64 { return uint64(k) }))
+		}
+	}
+}
+
+// The hash used by Map before keys were hashed by type,
+// kept for benchmark comparison
+func hashSprintf[key comparable](k key) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	h.Write([]byte(fmt.Sprintf("%v", k)))
+	return h.Sum64()
+}
+
+func BenchmarkHash(b *testing.B) {
+	b.Run("int/sprintf", func(b *testing.B) {
+		for i := range b.N {
+			hashSprintf(i)
+		}
+	})
+	b.Run("int/comparable", func(b *testing.B) {
+		for i := range b.N {
+			hash(i)
+		}
+	})
+	b.Run("string/sprintf", func(b *testing.B) {
+		for range b.N {
+			hashSprintf("benchmark-key")
+		}
+	})
+	b.Run("string/comparable", func(b *testing.B) {
+		for range b.N {
+			hash("benchmark-key")
+		}
+	})
+}
+
+func BenchmarkMap(b *testing.B) {
+	const size = 1 << 12
+
+	keys := make([]string, size)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	for _, bm := range []struct {
+		name   string
+		hasher Hasher[string]
+	}{
+		{name: "sprintf", hasher: hashSprintf[string]},
+		{name: "comparable", hasher: hash[string]},
+	} {
+		b.Run("Put/"+bm.name, func(b *testing.B) {
+			m := NewMap[string, int](size, WithHasher(bm.hasher))
+			b.ReportAllocs()
+			for i := range b.N {
+				m.Put(keys[i%size], i)
+			}
+		})
+		b.Run("Get/"+bm.name, func(b *testing.B) {
+			m := NewMap[string, int](size, WithHasher(bm.hasher))
+			for i, k := range keys {
+				m.Put(k, i)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				m.Get(keys[i%size])
+			}
+		})
+	}
+}
+
+func TestNewMapWithOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options []MapOption
+		valid   bool
+	}{
+		{name: "defaults", options: nil, valid: true},
+		{name: "chained settings", options: []MapOption{WithLoadFactor(2.5), WithMinCapacity(16), WithGrowthPolicy(func(c int) int { return c * 4 }), WithResizeMode(SynchronousResize)}, valid: true},
+		{name: "open addressing settings", options: []MapOption{WithBackend(OpenAddressingBackend), WithLoadFactor(0.5), WithMinCapacity(100)}, valid: true},
+		{name: "zero load factor", options: []MapOption{WithLoadFactor(0)}, valid: false},
+		{name: "negative load factor", options: []MapOption{WithLoadFactor(-1)}, valid: false},
+		{name: "NaN load factor", options: []MapOption{WithLoadFactor(math.NaN())}, valid: false},
+		{name: "infinite load factor", options: []MapOption{WithLoadFactor(math.Inf(1))}, valid: false},
+		{name: "open addressing load factor of 1", options: []MapOption{WithBackend(OpenAddressingBackend), WithLoadFactor(1)}, valid: false},
+		{name: "zero capacity", options: []MapOption{WithMinCapacity(0)}, valid: false},
+		{name: "nil growth policy", options: []MapOption{WithGrowthPolicy(nil)}, valid: false},
+		{name: "unknown resize mode", options: []MapOption{WithResizeMode(ResizeMode(10))}, valid: false},
+		{name: "unknown backend", options: []MapOption{WithBackend(MapBackend(10))}, valid: false},
+		{name: "open addressing growth policy", options: []MapOption{WithBackend(OpenAddressingBackend), WithGrowthPolicy(func(c int) int { return c * 2 })}, valid: false},
+		{name: "open addressing background resize", options: []MapOption{WithBackend(OpenAddressingBackend), WithResizeMode(BackgroundResize)}, valid: false},
+		{name: "hasher for wrong key type", options: []MapOption{WithHasher(func(k int) uint64 { return 0 })}, valid: false},
+	}
+
+	t.Log("Given the need to validate Map options")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When constructing with %v", i, test.name)
+			{
+				m, err := NewMapWithOptions[string, int](test.options...)
+
+				if test.valid {
+					if err != nil {
+						t.Errorf("\t%d\t Expected valid options : %v", i, err)
+						continue
+					}
+					for n := range 100 {
+						m.Put(strconv.Itoa(n), n)
+					}
+					if m.Size() != 100 {
+						t.Errorf("\t%d\t Map with options has unexpected size 100 : %v", i, m.Size())
+					}
+					continue
+				}
+
+				if !errors.Is(err, ErrInvalidMapOption) {
+					t.Errorf("\t%d\t Expected ErrInvalidMapOption : %v", i, err)
+				}
+				if m != nil {
+					t.Errorf("\t%d\t Expected no map with invalid options", i)
+				}
+			}
+		}
+
+		t.Log("Testing NewMap with zero capacity")
+		{
+			m := NewMap[string, int](0)
+			m.Put("one", 1)
+			if v, ok := m.Get("one"); !ok || v != 1 {
+				t.Errorf("\t Get on zero capacity map expected 1 : %v", v)
+			}
+		}
+	}
+}
+
+func TestMapAtomic(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test atomic Map operations")
+	for _, backend := range mapBackends {
+		t.Logf("Using the %v backend", backend.name)
+
+		m := NewMap[string, int](1, WithBackend(backend.backend))
+
+		t.Log("Testing PutIfAbsent")
+		{
+			if v, present := m.PutIfAbsent("a", 1); present || v != 1 {
+				t.Errorf("\t PutIfAbsent on absent key expected 1, false : %v, %v", v, present)
+			}
+			if v, present := m.PutIfAbsent("a", 2); !present || v != 1 {
+				t.Errorf("\t PutIfAbsent on present key expected 1, true : %v, %v", v, present)
+			}
+		}
+
+		t.Log("Testing GetOrCompute")
+		{
+			calls := 0
+			f := func() int { calls++; return 10 }
+			if v, present := m.GetOrCompute("b", f); present || v != 10 {
+				t.Errorf("\t GetOrCompute on absent key expected 10, false : %v, %v", v, present)
+			}
+			if v, present := m.GetOrCompute("b", f); !present || v != 10 {
+				t.Errorf("\t GetOrCompute on present key expected 10, true : %v, %v", v, present)
+			}
+			if calls != 1 {
+				t.Errorf("\t GetOrCompute should compute once : %v", calls)
+			}
+		}
+
+		t.Log("Testing Compute")
+		{
+			if v, ok := m.Compute("c", func(old int, present bool) (int, bool) { return old + 5, true }); !ok || v != 5 {
+				t.Errorf("\t Compute adding key expected 5, true : %v, %v", v, ok)
+			}
+			if v, ok := m.Compute("c", func(old int, present bool) (int, bool) { return old * 2, present }); !ok || v != 10 {
+				t.Errorf("\t Compute updating key expected 10, true : %v, %v", v, ok)
+			}
+			if _, ok := m.Compute("c", func(old int, present bool) (int, bool) { return 0, false }); ok || m.ContainsKey("c") {
+				t.Errorf("\t Compute returning false should remove the key")
+			}
+			if _, ok := m.Compute("d", func(old int, present bool) (int, bool) { return 0, false }); ok || m.ContainsKey("d") {
+				t.Errorf("\t Compute returning false should not add the key")
+			}
+		}
+
+		t.Log("Testing Merge")
+		{
+			sum := func(old, v int) int { return old + v }
+			if v := m.Merge("e", 3, sum); v != 3 {
+				t.Errorf("\t Merge on absent key expected 3 : %v", v)
+			}
+			if v := m.Merge("e", 4, sum); v != 7 {
+				t.Errorf("\t Merge on present key expected 7 : %v", v)
+			}
+		}
+
+		t.Log("Testing CompareAndSwap and CompareAndDelete")
+		{
+			if m.CompareAndSwap("e", 1, 100) {
+				t.Errorf("\t CompareAndSwap with wrong old value should fail")
+			}
+			if !m.CompareAndSwap("e", 7, 100) {
+				t.Errorf("\t CompareAndSwap with correct old value should succeed")
+			}
+			if m.CompareAndSwap("missing", 0, 1) || m.ContainsKey("missing") {
+				t.Errorf("\t CompareAndSwap on absent key should not add it")
+			}
+			if m.CompareAndDelete("e", 7) {
+				t.Errorf("\t CompareAndDelete with wrong old value should fail")
+			}
+			if !m.CompareAndDelete("e", 100) || m.ContainsKey("e") {
+				t.Errorf("\t CompareAndDelete with correct old value should remove the key")
+			}
+		}
+
+		if m.Size() != 2 {
+			t.Errorf("\t Size after atomic operations expected 2 : %v", m.Size())
+		}
+	}
+}
+
+func TestMapAtomicConcurrent(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test atomic Map operations concurrently")
+	for _, backend := range mapBackends {
+		t.Logf("Using the %v backend", backend.name)
+
+		concurrency := 50
+		increments := 100
+
+		m := NewMap[int, int](1, WithBackend(backend.backend))
+
+		wg := sync.WaitGroup{}
+		wg.Add(concurrency)
+		winners := make(chan int, concurrency)
+
+		for g := range concurrency {
+			go func() {
+				defer wg.Done()
+				if _, present := m.PutIfAbsent(-1, g); !present {
+					winners <- g
+				}
+				for i := range increments {
+					// spread over many keys so the map resizes meanwhile
+					m.Merge(i, 1, func(old, v int) int { return old + v })
+				}
+			}()
+		}
+		wg.Wait()
+		close(winners)
+
+		if len(winners) != 1 {
+			t.Errorf("\t PutIfAbsent should succeed exactly once : %v", len(winners))
+		}
+		for i := range increments {
+			if v, _ := m.Get(i); v != concurrency {
+				t.Errorf("\t Merge should count every increment of %v, %v : %v", i, concurrency, v)
+			}
+		}
+	}
+}
+
+func TestMapStress(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test Map operations hammering identical keys")
+	for _, backend := range mapBackends {
+		t.Logf("Using the %v backend", backend.name)
+
+		concurrency := 64
+
+		t.Logf("Testing %v concurrent Puts of the same new key", concurrency)
+		{
+			m := NewMap[int, int](1, WithBackend(backend.backend))
+
+			for round := range 50 {
+				start := make(chan struct{})
+				wg := sync.WaitGroup{}
+				wg.Add(concurrency)
+				for g := range concurrency {
+					go func() {
+						defer wg.Done()
+						<-start
+						m.Put(round, g)
+					}()
+				}
+				close(start)
+				wg.Wait()
+
+				if m.Size() != round+1 {
+					t.Fatalf("\t Concurrent Puts of one key should add it once %v : %v", round+1, m.Size())
+				}
+			}
+		}
+
+		t.Logf("Testing mixed operations on a few keys from %v goroutines", concurrency)
+		{
+			m := NewMap[int, int](1, WithBackend(backend.backend))
+			keys := 8
+			operations := 2000
+			counter := -1
+
+			start := make(chan struct{})
+			wg := sync.WaitGroup{}
+			wg.Add(concurrency)
+			for g := range concurrency {
+				go func() {
+					defer wg.Done()
+					<-start
+					for i := range operations {
+						k := (g + i) % keys
+						switch i % 6 {
+						case 0:
+							m.Put(k, i)
+						case 1:
+							m.Remove(k)
+						case 2:
+							m.Get(k)
+						case 3:
+							m.PutIfAbsent(k, i)
+						case 4:
+							m.Compute(k, func(old int, present bool) (int, bool) { return old + 1, !present || old%2 == 0 })
+						case 5:
+							for range m.Keys() {
+							}
+						}
+						m.Merge(counter, 1, func(old, v int) int { return old + v })
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			if v, _ := m.Get(counter); v != concurrency*operations {
+				t.Errorf("\t Merge lost updates %v : %v", concurrency*operations, v)
+			}
+
+			seen := map[int]int{}
+			for k := range m.Keys() {
+				seen[k]++
+			}
+			for k, n := range seen {
+				if n != 1 {
+					t.Errorf("\t Key %v was present %v times", k, n)
+				}
+			}
+			if len(seen) != m.Size() {
+				t.Errorf("\t Size does not match the keys present %v : %v", len(seen), m.Size())
+			}
+
+			if chained, ok := m.store.(*chainedMap[int, int]); ok {
+				total := 0
+				for b := range chained.table.buckets {
+					n := 0
+					chained.table.buckets[b].Do(func(e MapEntry[int, int]) { n++ })
+					if n != chained.table.buckets[b].size {
+						t.Errorf("\t Bucket %v size is inconsistent %v : %v", b, chained.table.buckets[b].size, n)
+					}
+					total += n
+				}
+				if total != m.Size() {
+					t.Errorf("\t Buckets do not contain the expected number of items %v : %v", m.Size(), total)
+				}
+			}
+		}
+	}
+}
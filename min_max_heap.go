@@ -0,0 +1,231 @@
+package godatastructures
+
+import (
+	"iter"
+	"math/bits"
+	"sync"
+)
+
+// Double ended priority queue using supplied compare function, as for
+// NewHeap. The min is the value Heap would put on top, and the max the
+// value it would put last.
+// Held as a single array in which elements on even levels are smaller
+// than all their descendants, and on odd levels larger, so the min is
+// the root and the max one of its children
+type MinMaxHeap[val any] struct {
+	slice   []val
+	compare func(v1, v2 val) int
+	mutex   sync.RWMutex
+}
+
+// constructor, with the backing slice sized for capacity values
+func NewMinMaxHeap[val any](capacity int, f func(val, val) int) *MinMaxHeap[val] {
+	h := MinMaxHeap[val]{
+		slice:   make([]val, 0, max(capacity, 0)),
+		compare: f,
+	}
+	return &h
+}
+
+// Get the smallest value without removing it
+func (h *MinMaxHeap[val]) PeekMin() (value val, ok bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if len(h.slice) == 0 {
+		return
+	}
+	return h.slice[0], true
+}
+
+// Get the largest value without removing it
+func (h *MinMaxHeap[val]) PeekMax() (value val, ok bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if len(h.slice) == 0 {
+		return
+	}
+	return h.slice[h.maxIndex()], true
+}
+
+// Get and remove the smallest value
+func (h *MinMaxHeap[val]) PopMin() (value val, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.slice) == 0 {
+		return
+	}
+	return h.removeAt(0), true
+}
+
+// Get and remove the largest value
+func (h *MinMaxHeap[val]) PopMax() (value val, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.slice) == 0 {
+		return
+	}
+	return h.removeAt(h.maxIndex()), true
+}
+
+// Puts the value on the heap
+func (h *MinMaxHeap[val]) Put(value val) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.slice = append(h.slice, value)
+	h.bubbleUp(len(h.slice) - 1)
+}
+
+// Gets the size of the heap
+func (h *MinMaxHeap[val]) Size() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.slice)
+}
+
+// Returns an iterator over the values of the heap from smallest to
+// largest, without removing them. The heap is copied under the read
+// lock when iteration starts, so the loop body may safely modify the heap
+func (h *MinMaxHeap[val]) All() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		h.mutex.RLock()
+		c := NewMinMaxHeap(len(h.slice), h.compare)
+		c.slice = append(c.slice, h.slice...)
+		h.mutex.RUnlock()
+
+		for v, ok := c.PopMin(); ok; v, ok = c.PopMin() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the values of the heap from largest to
+// smallest, without removing them, from a copy taken when iteration starts
+func (h *MinMaxHeap[val]) Backward() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		h.mutex.RLock()
+		c := NewMinMaxHeap(len(h.slice), h.compare)
+		c.slice = append(c.slice, h.slice...)
+		h.mutex.RUnlock()
+
+		for v, ok := c.PopMax(); ok; v, ok = c.PopMax() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Used internally to find the index of the largest value, the larger
+// of the root's children, or the root if it has none
+// Must be called with the lock held
+func (h *MinMaxHeap[val]) maxIndex() int {
+	switch len(h.slice) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.compare(h.slice[1], h.slice[2]) < 0 {
+		return 2
+	}
+	return 1
+}
+
+// Used internally to remove the value at the index, filling its place
+// with the last value and moving that down to its correct location
+// Must be called with the write lock held
+func (h *MinMaxHeap[val]) removeAt(idx int) val {
+	value := h.slice[idx]
+	last := len(h.slice) - 1
+	h.slice[idx] = h.slice[last]
+	var zero val
+	h.slice[last] = zero
+	h.slice = h.slice[:last]
+
+	if idx < last {
+		h.bubbleDown(idx)
+	}
+	return value
+}
+
+// Used internally to check whether the index is on a min level
+func onMinLevel(idx int) bool {
+	return bits.Len(uint(idx+1))%2 == 1
+}
+
+// Used internally to check whether the value at i belongs above the
+// value at j, on the min levels if minLevel is true, else the max levels
+func (h *MinMaxHeap[val]) before(i int, j int, minLevel bool) bool {
+	if minLevel {
+		return h.compare(h.slice[i], h.slice[j]) < 0
+	}
+	return h.compare(h.slice[i], h.slice[j]) > 0
+}
+
+// Moves the element at the index up into correct heap location
+// An element beyond its parent belongs on the other level type, so
+// swaps with it first, then moves up through grandparents of its type
+func (h *MinMaxHeap[val]) bubbleUp(idx int) {
+	if idx == 0 {
+		return
+	}
+	parentIdx := (idx - 1) / 2
+
+	minLevel := onMinLevel(idx)
+	if h.before(parentIdx, idx, minLevel) {
+		h.slice[idx], h.slice[parentIdx] = h.slice[parentIdx], h.slice[idx]
+		idx, minLevel = parentIdx, !minLevel
+	}
+
+	for idx >= 3 {
+		grandparentIdx := (idx - 3) / 4
+		if !h.before(idx, grandparentIdx, minLevel) {
+			return
+		}
+		h.slice[idx], h.slice[grandparentIdx] = h.slice[grandparentIdx], h.slice[idx]
+		idx = grandparentIdx
+	}
+}
+
+// Moves the element at the index down into correct heap location,
+// through the grandchildren of its level type, swapping with the
+// parent on the way where the element belongs on the other type
+func (h *MinMaxHeap[val]) bubbleDown(idx int) {
+	minLevel := onMinLevel(idx)
+
+	for {
+		// the best of the children and grandchildren
+		best, grandchild := -1, false
+		first := 2*idx + 1
+		for c := first; c < min(first+2, len(h.slice)); c++ {
+			if best < 0 || h.before(c, best, minLevel) {
+				best, grandchild = c, false
+			}
+			for g := 2*c + 1; g < min(2*c+3, len(h.slice)); g++ {
+				if h.before(g, best, minLevel) {
+					best, grandchild = g, true
+				}
+			}
+		}
+		if best < 0 || !h.before(best, idx, minLevel) {
+			return
+		}
+
+		h.slice[idx], h.slice[best] = h.slice[best], h.slice[idx]
+		if !grandchild {
+			return
+		}
+		if parentIdx := (best - 1) / 2; h.before(parentIdx, best, minLevel) {
+			h.slice[best], h.slice[parentIdx] = h.slice[parentIdx], h.slice[best]
+		}
+		idx = best
+	}
+}
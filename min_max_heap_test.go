@@ -0,0 +1,86 @@
+package godatastructures
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestMinMaxHeap(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test MinMaxHeap against a sorted slice")
+	{
+		for i, compareFunc := range []func(i, j int) int{SortAscendingInt, SortDescendingInt} {
+			t.Logf("\tTest: %d\t When testing random puts and pops", i)
+			{
+				h := NewMinMaxHeap(10, compareFunc)
+				if v, ok := h.PopMin(); ok {
+					t.Errorf("\t%d\t PopMin on empty heap returned a value: %v", i, v)
+				}
+				if v, ok := h.PeekMax(); ok {
+					t.Errorf("\t%d\t PeekMax on empty heap returned a value: %v", i, v)
+				}
+
+				r := rand.New(rand.NewSource(int64(i)))
+				sorted := []int{}
+				for range 2000 {
+					switch op := r.Intn(4); {
+					case op < 2 || len(sorted) == 0:
+						v := r.Intn(500)
+						h.Put(v)
+						sorted = append(sorted, v)
+						slices.SortFunc(sorted, compareFunc)
+					case op == 2:
+						if v, ok := h.PopMin(); !ok || v != sorted[0] {
+							t.Errorf("\t%d\t PopMin expected %v : %v %v", i, sorted[0], v, ok)
+						}
+						sorted = sorted[1:]
+					default:
+						if v, ok := h.PopMax(); !ok || v != sorted[len(sorted)-1] {
+							t.Errorf("\t%d\t PopMax expected %v : %v %v", i, sorted[len(sorted)-1], v, ok)
+						}
+						sorted = sorted[:len(sorted)-1]
+					}
+
+					if h.Size() != len(sorted) {
+						t.Fatalf("\t%d\t Size expected %v : %v", i, len(sorted), h.Size())
+					}
+					if len(sorted) > 0 {
+						if v, _ := h.PeekMin(); v != sorted[0] {
+							t.Errorf("\t%d\t PeekMin expected %v : %v", i, sorted[0], v)
+						}
+						if v, _ := h.PeekMax(); v != sorted[len(sorted)-1] {
+							t.Errorf("\t%d\t PeekMax expected %v : %v", i, sorted[len(sorted)-1], v)
+						}
+					}
+				}
+
+				if values := slices.Collect(h.All()); !slices.Equal(values, sorted) {
+					t.Errorf("\t%d\t All expected %v : %v", i, sorted, values)
+				}
+				backward := slices.Clone(sorted)
+				slices.Reverse(backward)
+				if values := slices.Collect(h.Backward()); !slices.Equal(values, backward) {
+					t.Errorf("\t%d\t Backward expected %v : %v", i, backward, values)
+				}
+			}
+		}
+	}
+
+	t.Log("Given the need to test a bounded top-K buffer")
+	{
+		const k = 5
+		h := NewMinMaxHeap(k+1, SortDescendingInt)
+		for _, v := range rand.New(rand.NewSource(2)).Perm(100) {
+			h.Put(v)
+			if h.Size() > k {
+				h.PopMax() // evict the worst
+			}
+		}
+		expected := []int{99, 98, 97, 96, 95}
+		if values := slices.Collect(h.All()); !slices.Equal(values, expected) {
+			t.Errorf("\t Top-K expected %v : %v", expected, values)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package godatastructures
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// Heap held as a tree in which each node keeps its children in a linked
+// list. Put and Merge are O(1), simply linking two trees, and Get is
+// O(log n) amortised, pairing up the children of the removed root.
+// Update to a smaller value is O(1) amortised, cutting the node out and
+// linking it back in at the root
+type PairingHeap[val any] struct {
+	root    *PairingHandle[val]
+	size    int
+	compare func(v1, v2 val) int
+	group   *pairingGroup         // handles of this heap belong to its group
+	pairs   []*PairingHandle[val] // reused by pair, guarded by the write lock
+	mutex   sync.RWMutex
+}
+
+// Handle to a value on a PairingHeap, returned by Put, which is also
+// the node holding the value in the heap's tree
+type PairingHandle[val any] struct {
+	value   val
+	child   *PairingHandle[val]          // first child
+	sibling *PairingHandle[val]          // next sibling
+	prev    *PairingHandle[val]          // previous sibling, or parent if the first child
+	group   atomic.Pointer[pairingGroup] // nil once removed
+}
+
+// Identifies the heap a handle belongs to. When a heap is merged into
+// another its group is forwarded to the other's, so the handles of both
+// then belong to the merged heap without being visited. A handle's group
+// and the forwarding are atomic, as a handle may be checked under the
+// lock of a heap other than the one changing it
+type pairingGroup struct {
+	merged atomic.Pointer[pairingGroup]
+}
+
+var _ PriorityQueue[int, *PairingHandle[int]] = (*PairingHeap[int])(nil)
+
+// constructor
+func NewPairingHeap[val any](f func(val, val) int) *PairingHeap[val] {
+	h := PairingHeap[val]{
+		compare: f,
+		group:   &pairingGroup{},
+	}
+	return &h
+}
+
+// Get the item at the top of the heap without removing it
+func (h *PairingHeap[val]) Peek() (value val, ok bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if h.root == nil {
+		return
+	}
+	return h.root.value, true
+}
+
+// Get and remove the value from the top of the heap
+func (h *PairingHeap[val]) Get() (value val, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.root == nil {
+		return
+	}
+	return h.remove(h.root), true
+}
+
+// Puts the value on the heap, returning a handle to it
+func (h *PairingHeap[val]) Put(value val) *PairingHandle[val] {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	handle := &PairingHandle[val]{value: value}
+	handle.group.Store(h.group)
+	h.root = h.link(h.root, handle)
+	h.size++
+
+	return handle
+}
+
+// Replaces the value of the handle and moves it to its new heap location
+// Returns false if the handle is no longer on the heap
+func (h *PairingHeap[val]) Update(handle *PairingHandle[val], value val) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.holds(handle) {
+		return false
+	}
+	if h.compare(value, handle.value) > 0 {
+		// a larger value may belong below its children, so they are
+		// paired up in its place and it is linked back in on its own
+		h.remove(handle)
+		handle.value = value
+		handle.group.Store(h.group)
+		h.root = h.link(h.root, handle)
+		h.size++
+		return true
+	}
+	handle.value = value
+	if handle != h.root {
+		h.cut(handle)
+		h.root = h.link(h.root, handle)
+	}
+	return true
+}
+
+// Removes the value of the handle from the heap
+// boolean ok indicates whether the handle was still on the heap
+func (h *PairingHeap[val]) Remove(handle *PairingHandle[val]) (value val, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.holds(handle) {
+		return
+	}
+	return h.remove(handle), true
+}
+
+// Moves every value of the other heap onto this heap in O(1), leaving
+// the other heap empty. Handles of the other heap remain valid, now for
+// this heap. The other heap is emptied under its own lock, so the locks
+// of both heaps are never held together
+func (h *PairingHeap[val]) Merge(other *PairingHeap[val]) {
+	other.mutex.Lock()
+	root, size, group := other.root, other.size, other.group
+	other.root, other.size, other.group = nil, 0, &pairingGroup{}
+	other.mutex.Unlock()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	group.merged.Store(h.group)
+	h.root = h.link(h.root, root)
+	h.size += size
+}
+
+// Gets the size of the heap
+func (h *PairingHeap[val]) Size() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.size
+}
+
+// Returns an iterator over the values of the heap in sorted order,
+// without removing them. The values are copied under the read lock
+// when iteration starts, so the loop body may safely modify the heap
+func (h *PairingHeap[val]) All() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		h.mutex.RLock()
+		c := NewHeap(h.size, h.compare)
+		values := make([]val, 0, h.size)
+		for n := range h.nodes() {
+			values = append(values, n.value)
+		}
+		h.mutex.RUnlock()
+
+		c.appendValues(values)
+		c.heapify()
+		for v, ok := c.Get(); ok; v, ok = c.Get() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Used internally to iterate every node of the tree, depth first
+// Must be called with the lock held
+func (h *PairingHeap[val]) nodes() iter.Seq[*PairingHandle[val]] {
+	return func(yield func(*PairingHandle[val]) bool) {
+		stack := []*PairingHandle[val]{}
+		if h.root != nil {
+			stack = append(stack, h.root)
+		}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(n) {
+				return
+			}
+			for c := n.child; c != nil; c = c.sibling {
+				stack = append(stack, c)
+			}
+		}
+	}
+}
+
+// Used internally to check the handle is on this heap, following
+// the chain of merged groups and shortening it for later checks
+// Must be called with the lock held
+func (h *PairingHeap[val]) holds(handle *PairingHandle[val]) bool {
+	if handle == nil {
+		return false
+	}
+	group := handle.group.Load()
+	if group == nil {
+		return false
+	}
+	for next := group.merged.Load(); next != nil; next = group.merged.Load() {
+		if skip := next.merged.Load(); skip != nil {
+			group.merged.CompareAndSwap(next, skip)
+		}
+		group = next
+	}
+	return group == h.group
+}
+
+// Used internally to remove a node, replacing it with its children
+// paired up into a single tree
+// Must be called with the write lock held
+func (h *PairingHeap[val]) remove(n *PairingHandle[val]) val {
+	if n == h.root {
+		h.root = h.pair(n.child)
+	} else {
+		h.cut(n)
+		h.root = h.link(h.root, h.pair(n.child))
+	}
+	n.child = nil
+	n.group.Store(nil)
+	h.size--
+	return n.value
+}
+
+// Used internally to detach a node other than the root, with its
+// children, from its parent and siblings
+// Must be called with the write lock held
+func (h *PairingHeap[val]) cut(n *PairingHandle[val]) {
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+}
+
+// Used internally to link two detached trees, making the root with
+// the larger value the first child of the other
+// Must be called with the write lock held
+func (h *PairingHeap[val]) link(a *PairingHandle[val], b *PairingHandle[val]) *PairingHandle[val] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if h.compare(b.value, a.value) < 0 {
+		a, b = b, a
+	}
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	b.prev = a
+	a.child = b
+	return a
+}
+
+// Used internally to combine a list of siblings into a single tree,
+// linking them in pairs from the left, then linking the pairs from
+// the right, which keeps Get O(log n) amortised
+// Must be called with the write lock held
+func (h *PairingHeap[val]) pair(first *PairingHandle[val]) *PairingHandle[val] {
+	pairs := h.pairs[:0]
+	for first != nil {
+		a, b := first, first.sibling
+		if b == nil {
+			first = nil
+		} else {
+			first = b.sibling
+			b.prev, b.sibling = nil, nil
+		}
+		a.prev, a.sibling = nil, nil
+		pairs = append(pairs, h.link(a, b))
+	}
+
+	var root *PairingHandle[val]
+	for i := len(pairs) - 1; i >= 0; i-- {
+		root = h.link(pairs[i], root)
+		pairs[i] = nil
+	}
+	h.pairs = pairs
+	return root
+}
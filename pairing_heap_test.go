@@ -0,0 +1,155 @@
+package godatastructures
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestPairingHeap(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test PairingHeap ordering")
+	{
+		h := NewPairingHeap(SortAscendingInt)
+		if v, ok := h.Get(); ok {
+			t.Errorf("\t Get on empty heap returned a value: %v", v)
+		}
+		if v, ok := h.Peek(); ok {
+			t.Errorf("\t Peek on empty heap returned a value: %v", v)
+		}
+
+		data := rand.New(rand.NewSource(1)).Perm(200)
+		handles := make([]*PairingHandle[int], len(data))
+		for i, n := range data {
+			handles[i] = h.Put(n)
+		}
+		if h.Size() != len(data) {
+			t.Errorf("\t Size expected %v : %v", len(data), h.Size())
+		}
+		sorted := slices.Clone(data)
+		slices.Sort(sorted)
+		if values := slices.Collect(h.All()); !slices.Equal(values, sorted) {
+			t.Errorf("\t All should yield values in heap order : %v", values)
+		}
+
+		t.Log("\t\t Testing Update and Remove by handle")
+		// take the minimum first so the tree has children to cut from
+		h.Get()
+		handles[slices.Index(data, 0)] = nil
+		expected := []int{}
+		for i, n := range data {
+			switch {
+			case n == 0:
+			case n%10 == 0:
+				if v, ok := h.Remove(handles[i]); !ok || v != n {
+					t.Errorf("\t Remove expected %v : %v %v", n, v, ok)
+				}
+			case n%3 == 0:
+				h.Update(handles[i], n+1000) // increase
+				expected = append(expected, n+1000)
+			case n%2 == 1:
+				h.Update(handles[i], -n) // decrease
+				expected = append(expected, -n)
+			default:
+				expected = append(expected, n)
+			}
+		}
+		slices.Sort(expected)
+		for _, want := range expected {
+			if v, ok := h.Get(); !ok || v != want {
+				t.Errorf("\t Get expected %v : %v %v", want, v, ok)
+				break
+			}
+		}
+		if h.Size() != 0 {
+			t.Errorf("\t Size after Get on all values expected 0 : %v", h.Size())
+		}
+		if _, ok := h.Remove(handles[1]); ok {
+			t.Errorf("\t Remove on a removed handle should fail")
+		}
+		if h.Update(handles[1], 0) {
+			t.Errorf("\t Update on a removed handle should fail")
+		}
+	}
+
+	t.Log("Given the need to test PairingHeap Merge")
+	{
+		a, b, c := NewPairingHeap(SortAscendingInt), NewPairingHeap(SortAscendingInt), NewPairingHeap(SortAscendingInt)
+		ha := a.Put(5)
+		hb := b.Put(7)
+		hc := c.Put(9)
+		a.Put(1)
+		b.Put(3)
+
+		if b.Update(ha, 0) {
+			t.Errorf("\t Update with another heap's handle should fail")
+		}
+
+		b.Merge(c)
+		a.Merge(b)
+		a.Merge(a)
+		if b.Size() != 0 || c.Size() != 0 {
+			t.Errorf("\t Merge should empty the other heap : %v %v", b.Size(), c.Size())
+		}
+		if b.Update(hb, 0) || c.Update(hc, 0) {
+			t.Errorf("\t Handles of a merged heap should not be valid for it")
+		}
+		if !a.Update(hc, 0) || !a.Update(hb, 2) || !a.Update(ha, 4) {
+			t.Errorf("\t Handles of merged heaps should be valid for the merged heap")
+		}
+
+		hd := b.Put(6)
+		a.Merge(b)
+		if !a.Update(hd, 8) {
+			t.Errorf("\t Handles put after a merge should follow a later merge")
+		}
+
+		expected := []int{0, 1, 2, 3, 4, 8}
+		if values := slices.Collect(a.All()); !slices.Equal(values, expected) {
+			t.Errorf("\t All after Merge expected %v : %v", expected, values)
+		}
+	}
+}
+
+func TestPairingHeapForeignHandles(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test handles passed to another heap concurrently")
+	{
+		compare := func(a, b int) int { return a - b }
+		owner, other := NewPairingHeap(compare), NewPairingHeap(compare)
+		other.Put(0)
+
+		handles := make(chan *PairingHandle[int], 100)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			defer close(handles)
+			for i := range 2000 {
+				handle := owner.Put(i)
+				handles <- handle
+				owner.Update(handle, i+1)
+				owner.Remove(handle)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for handle := range handles {
+				if other.Update(handle, -1) {
+					t.Errorf("\t Update on another heap should fail")
+				}
+				if _, ok := other.Remove(handle); ok {
+					t.Errorf("\t Remove on another heap should fail")
+				}
+			}
+		}()
+		wg.Wait()
+
+		if owner.Size() != 0 || other.Size() != 1 {
+			t.Errorf("\t Foreign handles should not change either heap : %d %d", owner.Size(), other.Size())
+		}
+	}
+}
@@ -1,6 +1,15 @@
 package godatastructures
 
+import "iter"
+
 // This class implements a set interface
+//
+// Operations involving more than one set read each operand from a
+// snapshot copied, or a batch of lookups made, at a single point in time
+// under that set's own locks. The locks of two sets are never held
+// together, so s.Union(s), or a.Union(b) and b.Union(a) running
+// concurrently, cannot deadlock. Each operand is read consistently,
+// though two operands are not necessarily read at the same instant
 type Set[val comparable] struct {
 	m *Map[val, struct{}]
 }
@@ -25,12 +34,7 @@ func (s *Set[val]) Add(v val) {
 
 // Add a set to the set
 func (s *Set[val]) AddSet(t *Set[val]) {
-	for b := range t.m.buckets {
-		t.m.buckets[b].Do(
-			func(m *MapEntry[val, struct{}]) {
-				s.Add(m.key)
-			})
-	}
+	s.AddSlice(t.snapshot())
 }
 
 // Add a slice of values to the set
@@ -60,29 +64,20 @@ func (s *Set[val]) Contains(v val) bool {
 // Returns a union of set s and t
 func (s *Set[val]) Union(t *Set[val]) (union *Set[val]) {
 	union = NewSet[val]()
-	union.AddSet(s)
-	union.AddSet(t)
+	union.AddSlice(s.snapshot())
+	union.AddSlice(t.snapshot())
 	return
 }
 
 // Returns intersection of set s with t
 func (s *Set[val]) Intersection(t *Set[val]) (intersection *Set[val]) {
 	intersection = NewSet[val]()
-	for b := range s.m.buckets {
-		s.m.buckets[b].Do(
-			func(m *MapEntry[val, struct{}]) {
-				if t.Contains(m.key) {
-					intersection.Add(m.key)
-				}
-			})
-	}
-	for b := range t.m.buckets {
-		t.m.buckets[b].Do(
-			func(m *MapEntry[val, struct{}]) {
-				if s.Contains(m.key) {
-					intersection.Add(m.key)
-				}
-			})
+	small, large := smallerFirst(s, t)
+	values := small.snapshot()
+	for i, present := range large.m.store.containsEach(values) {
+		if present {
+			intersection.Add(values[i])
+		}
 	}
 	return
 }
@@ -90,31 +85,122 @@ func (s *Set[val]) Intersection(t *Set[val]) (intersection *Set[val]) {
 // Return difference of set s with t
 func (s *Set[val]) Difference(t *Set[val]) (difference *Set[val]) {
 	difference = NewSet[val]()
-	difference.AddSet(s)
-	for b := range t.m.buckets {
-		t.m.buckets[b].Do(
-			func(m *MapEntry[val, struct{}]) {
-				difference.Remove(m.key)
-			})
+	values := s.snapshot()
+	for i, present := range t.m.store.containsEach(values) {
+		if !present {
+			difference.Add(values[i])
+		}
 	}
+	return
+}
 
+// Returns the values in exactly one of set s and t
+func (s *Set[val]) SymmetricDifference(t *Set[val]) (difference *Set[val]) {
+	difference = NewSet[val]()
+	difference.AddSlice(s.snapshot())
+	for _, v := range t.snapshot() {
+		if difference.Contains(v) {
+			difference.Remove(v)
+		} else {
+			difference.Add(v)
+		}
+	}
 	return
 }
 
-// func (s *Set[val]) SubSet(t *Set[val]) bool {
-// }
+// Return true if every value of s is in t
+func (s *Set[val]) IsSubset(t *Set[val]) bool {
+	if s.Size() > t.Size() {
+		return false
+	}
+	for _, present := range t.m.store.containsEach(s.snapshot()) {
+		if !present {
+			return false
+		}
+	}
+	return true
+}
+
+// Return true if every value of t is in s
+func (s *Set[val]) IsSuperset(t *Set[val]) bool {
+	return t.IsSubset(s)
+}
+
+// Return true if s and t have no values in common
+func (s *Set[val]) IsDisjoint(t *Set[val]) bool {
+	small, large := smallerFirst(s, t)
+	for _, present := range large.m.store.containsEach(small.snapshot()) {
+		if present {
+			return false
+		}
+	}
+	return true
+}
 
-// Return the values of the set in a slice
+// Return true if s and t contain the same values. As the values of a set
+// are distinct, s equals t when they have the same size and every value
+// of s is in t
+func (s *Set[val]) Equals(t *Set[val]) bool {
+	if s == t {
+		return true
+	}
+	values := s.snapshot()
+	if len(values) != t.Size() {
+		return false
+	}
+	for _, present := range t.m.store.containsEach(values) {
+		if !present {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove from s every value not in t
+func (s *Set[val]) RetainAll(t *Set[val]) {
+	values := s.snapshot()
+	for i, present := range t.m.store.containsEach(values) {
+		if !present {
+			s.Remove(values[i])
+		}
+	}
+}
+
+// Remove from s every value in t
+func (s *Set[val]) RemoveSet(t *Set[val]) {
+	if t.Size() <= s.Size() {
+		s.RemoveSlice(t.snapshot())
+		return
+	}
+	values := s.snapshot()
+	for i, present := range t.m.store.containsEach(values) {
+		if present {
+			s.Remove(values[i])
+		}
+	}
+}
+
+// Return the values of the set, copied at a single point in time, in a slice
 func (s *Set[val]) Slice() []val {
-	sl := make([]val, s.m.Size())
+	return s.snapshot()
+}
+
+// Return an iterator over the values of the set, with the
+// same consistency guarantees as Map.All
+func (s *Set[val]) All() iter.Seq[val] {
+	return s.m.Keys()
+}
+
+// Used internally to copy the values present at a single point in time
+func (s *Set[val]) snapshot() []val {
+	return s.m.keySnapshot()
+}
 
-	idx := 0
-	for b := range s.m.buckets {
-		s.m.buckets[b].Do(
-			func(m *MapEntry[val, struct{}]) {
-				sl[idx] = m.key
-				idx++
-			})
+// Returns the two sets ordered smallest first, so operations can
+// iterate the smaller set and probe the larger one
+func smallerFirst[val comparable](s *Set[val], t *Set[val]) (small *Set[val], large *Set[val]) {
+	if s.Size() <= t.Size() {
+		return s, t
 	}
-	return sl
+	return t, s
 }
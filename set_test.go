@@ -1,6 +1,8 @@
 package godatastructures
 
 import (
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -203,3 +205,193 @@ func TestSet(t *testing.T) {
 	}
 
 }
+
+func TestSetIterator(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test Set iteration")
+	{
+		s := NewSet[int]()
+		s.AddSlice([]int{1, 2, 3, 4, 5, 5})
+
+		values := slices.Collect(s.All())
+		if !cmp.Equal(values, s.Slice(), cmpopts.SortSlices(func(a, b int) bool { return a < b })) {
+			t.Errorf("\t All should yield the set values %v : %v", s.Slice(), values)
+		}
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s, t          []int
+		symmetricDiff []int
+		subset        bool
+		superset      bool
+		disjoint      bool
+		equal         bool
+	}{
+		{s: []int{1, 2}, t: []int{1, 2, 3}, symmetricDiff: []int{3}, subset: true},
+		{s: []int{1, 2, 3}, t: []int{1, 2}, symmetricDiff: []int{3}, superset: true},
+		{s: []int{1, 2, 3}, t: []int{3, 2, 1}, symmetricDiff: []int{}, subset: true, superset: true, equal: true},
+		{s: []int{1, 2}, t: []int{3, 4, 5}, symmetricDiff: []int{1, 2, 3, 4, 5}, disjoint: true},
+		{s: []int{1, 2, 6}, t: []int{2, 3}, symmetricDiff: []int{1, 3, 6}},
+		{s: []int{}, t: []int{1}, symmetricDiff: []int{1}, subset: true, disjoint: true},
+		{s: []int{}, t: []int{}, symmetricDiff: []int{}, subset: true, superset: true, disjoint: true, equal: true},
+	}
+
+	sorted := cmpopts.SortSlices(func(a, b int) bool { return a < b })
+
+	t.Log("Given the need to test Set algebra")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing %v against %v ", i, test.s, test.t)
+			{
+				s, u := NewSet[int](), NewSet[int]()
+				s.AddSlice(test.s)
+				u.AddSlice(test.t)
+
+				if s.IsSubset(u) != test.subset {
+					t.Errorf("\t%d\t IsSubset expected %v", i, test.subset)
+				}
+				if s.IsSuperset(u) != test.superset {
+					t.Errorf("\t%d\t IsSuperset expected %v", i, test.superset)
+				}
+				if s.IsDisjoint(u) != test.disjoint || u.IsDisjoint(s) != test.disjoint {
+					t.Errorf("\t%d\t IsDisjoint expected %v", i, test.disjoint)
+				}
+				if s.Equals(u) != test.equal || u.Equals(s) != test.equal {
+					t.Errorf("\t%d\t Equals expected %v", i, test.equal)
+				}
+				if !s.Equals(s) || !s.IsSubset(s) || !s.IsSuperset(s) {
+					t.Errorf("\t%d\t Set should equal, and be a subset and superset of, itself", i)
+				}
+
+				sd := s.SymmetricDifference(u)
+				if !cmp.Equal(sd.Slice(), test.symmetricDiff, sorted, cmpopts.EquateEmpty()) {
+					t.Errorf("\t%d\t SymmetricDifference expected %v : %v", i, test.symmetricDiff, sd.Slice())
+				}
+
+				t.Logf("\t%d\t Testing in place operations match their allocating variants", i)
+
+				retained := NewSet[int]()
+				retained.AddSlice(test.s)
+				retained.RetainAll(u)
+				if !retained.Equals(s.Intersection(u)) {
+					t.Errorf("\t%d\t RetainAll expected %v : %v", i, s.Intersection(u).Slice(), retained.Slice())
+				}
+
+				removed := NewSet[int]()
+				removed.AddSlice(test.s)
+				removed.RemoveSet(u)
+				if !removed.Equals(s.Difference(u)) {
+					t.Errorf("\t%d\t RemoveSet expected %v : %v", i, s.Difference(u).Slice(), removed.Slice())
+				}
+
+				self := NewSet[int]()
+				self.AddSlice(test.s)
+				self.RemoveSet(self)
+				if self.Size() != 0 {
+					t.Errorf("\t%d\t RemoveSet of self should empty the set : %v", i, self.Slice())
+				}
+			}
+		}
+	}
+}
+
+func TestSetConcurrent(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test Set operations alongside concurrent writers")
+	{
+		a, b := NewSet[int](), NewSet[int]()
+		for v := range 100 {
+			a.Add(v)
+			b.Add(v + 50)
+		}
+
+		done := make(chan struct{})
+		writers := sync.WaitGroup{}
+		for _, s := range []*Set[int]{a, b} {
+			writers.Add(1)
+			go func() {
+				defer writers.Done()
+				for i := 0; ; i++ {
+					select {
+					case <-done:
+						return
+					default:
+					}
+					// alternately add and remove a block of values outside
+					// the stable range, forcing the set to grow and shrink
+					v := 1000 + i%500
+					if (i/500)%2 == 0 {
+						s.Add(v)
+					} else {
+						s.Remove(v)
+					}
+				}
+			}()
+		}
+
+		readers := sync.WaitGroup{}
+		readers.Add(4)
+		go func() {
+			defer readers.Done()
+			for range 200 {
+				a.Union(b)
+				a.Union(a)
+				a.Intersection(b)
+				a.SymmetricDifference(b)
+			}
+		}()
+		go func() {
+			defer readers.Done()
+			for range 200 {
+				b.Union(a)
+				b.Difference(a)
+				b.IsDisjoint(a)
+				b.Equals(a)
+			}
+		}()
+		go func() {
+			defer readers.Done()
+			for range 200 {
+				slice := a.Slice()
+				seen := make(map[int]bool, len(slice))
+				for _, v := range slice {
+					if seen[v] {
+						t.Errorf("\t Slice contained %v twice", v)
+					}
+					seen[v] = true
+				}
+				for v := range 100 {
+					if !seen[v] {
+						t.Errorf("\t Slice missing stable value %v", v)
+					}
+				}
+			}
+		}()
+		go func() {
+			defer readers.Done()
+			for range 200 {
+				intersection := a.Intersection(b)
+				for v := 50; v < 100; v++ {
+					if !intersection.Contains(v) {
+						t.Errorf("\t Intersection missing stable value %v", v)
+					}
+				}
+				union := a.Union(b)
+				for v := range 150 {
+					if !union.Contains(v) {
+						t.Errorf("\t Union missing stable value %v", v)
+					}
+				}
+			}
+		}()
+		readers.Wait()
+		close(done)
+		writers.Wait()
+	}
+}
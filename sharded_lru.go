@@ -0,0 +1,173 @@
+package godatastructures
+
+import (
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// LRU cache partitioned by key hash across independent LRUCache shards,
+// each with its own lock, so operations on keys in different shards do
+// not contend. The capacity is divided between the shards and each
+// evicts its own least recently used entry, so eviction is LRU per shard
+// rather than across the whole cache.
+// Options apply to every shard, and a Weigher makes each shard's share
+// of the capacity a total weight
+type ShardedLRUCache[key comparable, val any] struct {
+	shards []*LRUCache[key, val]
+}
+
+// constructor
+// ok is false if shards is less than 1, cap is less than shards,
+// or any option is invalid
+func NewShardedLRUCache[key comparable, val any](cap int, shards int, options ...LRUOption) (cache *ShardedLRUCache[key, val], ok bool) {
+	cache, err := NewShardedLRUCacheWithOptions[key, val](cap, shards, options...)
+	return cache, err == nil
+}
+
+// constructor
+// Returns an error wrapping ErrInvalidLRUOption if shards is less than 1,
+// cap is less than shards, or any option is invalid
+func NewShardedLRUCacheWithOptions[key comparable, val any](cap int, shards int, options ...LRUOption) (*ShardedLRUCache[key, val], error) {
+
+	if shards < 1 {
+		return nil, fmt.Errorf("%w: shard count %d must be at least 1", ErrInvalidLRUOption, shards)
+	}
+	if cap < shards {
+		return nil, fmt.Errorf("%w: capacity %d must be at least the shard count %d", ErrInvalidLRUOption, cap, shards)
+	}
+
+	cache := &ShardedLRUCache[key, val]{
+		shards: make([]*LRUCache[key, val], shards),
+	}
+	for i := range cache.shards {
+		// the remainder of the capacity goes to the first shards
+		shardCap := cap / shards
+		if i < cap%shards {
+			shardCap++
+		}
+		shard, err := NewLRUCacheWithOptions[key, val](shardCap, options...)
+		if err != nil {
+			cache.Close()
+			return nil, err
+		}
+		cache.shards[i] = shard
+	}
+	return cache, nil
+}
+
+// Return the number of shards
+func (s *ShardedLRUCache[key, val]) Shards() int {
+	return len(s.shards)
+}
+
+// Return the capacity of the cache, the total of the shards' capacities
+func (s *ShardedLRUCache[key, val]) Cap() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Cap()
+	}
+	return total
+}
+
+// Return the current length of the cache, the total of the shards'
+// lengths, each taken under its own lock
+func (s *ShardedLRUCache[key, val]) Len() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+// Return the total weight of the entries, as for LRUCache.Weight
+func (s *ShardedLRUCache[key, val]) Weight() int64 {
+	var total int64
+	for _, shard := range s.shards {
+		total += shard.Weight()
+	}
+	return total
+}
+
+// Return the counts of hits and misses recorded by Get across all shards
+func (s *ShardedLRUCache[key, val]) Stats() CacheStats {
+	var total CacheStats
+	for _, shard := range s.shards {
+		stats := shard.Stats()
+		total.Hits += stats.Hits
+		total.Misses += stats.Misses
+	}
+	return total
+}
+
+// Check if the key is in the cache and has not expired.
+// Does not affect recency
+func (s *ShardedLRUCache[key, val]) Contains(k key) bool {
+	return s.shard(k).Contains(k)
+}
+
+// Return the value associated with the key, recording a hit or miss,
+// and move that item (if any) to the most recent position in its shard
+// boolean ok indicates presence of a value which has not expired
+func (s *ShardedLRUCache[key, val]) Get(k key) (value val, ok bool) {
+	return s.shard(k).Get(k)
+}
+
+// Put the value into the cache with the supplied key, as for LRUCache.Put
+func (s *ShardedLRUCache[key, val]) Put(k key, v val) {
+	s.shard(k).Put(k, v)
+}
+
+// Put the value into the cache with the supplied key, expiring after
+// the TTL, as for LRUCache.PutWithTTL
+func (s *ShardedLRUCache[key, val]) PutWithTTL(k key, v val, ttl time.Duration) {
+	s.shard(k).PutWithTTL(k, v, ttl)
+}
+
+// Remove the key from the cache
+// boolean ok indicates whether a value which had not expired was present
+func (s *ShardedLRUCache[key, val]) Remove(k key) (ok bool) {
+	return s.shard(k).Remove(k)
+}
+
+// Clear all values from the cache, one shard at a time
+func (s *ShardedLRUCache[key, val]) Clear() {
+	for _, shard := range s.shards {
+		shard.Clear()
+	}
+}
+
+// Remove every expired entry from the cache, one shard at a time
+func (s *ShardedLRUCache[key, val]) RemoveExpired() {
+	for _, shard := range s.shards {
+		shard.RemoveExpired()
+	}
+}
+
+// Set the function called with each key and value leaving the cache,
+// and the reason, or nil for none, as for LRUCache.OnEvict.
+// It may be called concurrently from different shards
+func (s *ShardedLRUCache[key, val]) OnEvict(f func(k key, v val, reason EvictReason)) {
+	for _, shard := range s.shards {
+		shard.OnEvict(f)
+	}
+}
+
+// Stop the janitor goroutines of the shards, if they were started
+// Safe to call more than once
+func (s *ShardedLRUCache[key, val]) Close() {
+	for _, shard := range s.shards {
+		if shard != nil {
+			shard.Close()
+		}
+	}
+}
+
+// Used internally to find the shard holding the key. The shard is
+// chosen by the high bits of the hash, as the maps within the shards
+// index by the low bits, which would otherwise be alike for every key
+// in a shard
+func (s *ShardedLRUCache[key, val]) shard(k key) *LRUCache[key, val] {
+	idx, _ := bits.Mul64(hash(k), uint64(len(s.shards)))
+	return s.shards[idx]
+}
@@ -0,0 +1,154 @@
+package godatastructures
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestShardedLRU(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test sharded LRU construction")
+	{
+		tests := []struct {
+			capacity int
+			shards   int
+			valid    bool
+		}{
+			{capacity: 10, shards: 1, valid: true},
+			{capacity: 10, shards: 3, valid: true},
+			{capacity: 4, shards: 4, valid: true},
+			{capacity: 3, shards: 4, valid: false},
+			{capacity: 10, shards: 0, valid: false},
+		}
+
+		for i, test := range tests {
+			s, err := NewShardedLRUCacheWithOptions[int, int](test.capacity, test.shards)
+			if test.valid != (err == nil) {
+				t.Errorf("\t%d\t Create with capacity %d and %d shards : %v", i, test.capacity, test.shards, err)
+				continue
+			}
+			if !test.valid {
+				if !errors.Is(err, ErrInvalidLRUOption) {
+					t.Errorf("\t%d\t Expected ErrInvalidLRUOption : %v", i, err)
+				}
+				continue
+			}
+			if s.Cap() != test.capacity || s.Shards() != test.shards {
+				t.Errorf("\t%d\t Expected capacity %d with %d shards : %d %d", i, test.capacity, test.shards, s.Cap(), s.Shards())
+			}
+		}
+	}
+
+	t.Log("Given the need to test sharded LRU operations")
+	{
+		const capacity = 64
+		s, ok := NewShardedLRUCache[string, int](capacity, 4)
+		if !ok {
+			t.Fatalf("\t Create sharded LRU failed")
+		}
+
+		evicted := 0
+		var mutex sync.Mutex
+		s.OnEvict(func(k string, v int, reason EvictReason) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if reason == EvictCapacity {
+				evicted++
+			}
+		})
+
+		for i := range 200 {
+			s.Put(fmt.Sprint(i), i)
+			if s.Len() > s.Cap() {
+				t.Fatalf("\t Len %d exceeds Cap %d", s.Len(), s.Cap())
+			}
+		}
+		if s.Len()+evicted != 200 {
+			t.Errorf("\t Len %d and evicted %d should total 200", s.Len(), evicted)
+		}
+		if v, ok := s.Get("199"); !ok || v != 199 {
+			t.Errorf("\t Most recent key should be present : %v %v", v, ok)
+		}
+		if _, ok := s.Get("0"); ok {
+			t.Errorf("\t Least recent key should have been evicted")
+		}
+		if stats := s.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+			t.Errorf("\t Stats expected 1 hit and 1 miss : %+v", stats)
+		}
+
+		if !s.Remove("199") || s.Contains("199") {
+			t.Errorf("\t Remove should remove the key")
+		}
+		s.Clear()
+		if s.Len() != 0 {
+			t.Errorf("\t Len after Clear expected 0 : %d", s.Len())
+		}
+	}
+}
+
+// Benchmark a single LRUCache against sharded caches as the number of
+// goroutines sharing them grows, over a mostly read zipf workload
+func BenchmarkShardedLRU(b *testing.B) {
+	const size, keys = 10000, 100000
+
+	// the operations the benchmark uses, common to both caches
+	type lruCache interface {
+		Get(k int) (int, bool)
+		Put(k int, v int)
+	}
+
+	caches := []struct {
+		name  string
+		cache func() lruCache
+	}{
+		{name: "LRUCache", cache: func() lruCache {
+			c, _ := NewLRUCache[int, int](size)
+			return c
+		}},
+		{name: "Sharded16", cache: func() lruCache {
+			c, _ := NewShardedLRUCache[int, int](size, 16)
+			return c
+		}},
+		{name: "Sharded64", cache: func() lruCache {
+			c, _ := NewShardedLRUCache[int, int](size, 64)
+			return c
+		}},
+	}
+
+	for _, goroutines := range []int{1, 4, 16, 64} {
+		for _, test := range caches {
+			b.Run(fmt.Sprintf("%s/goroutines=%d", test.name, goroutines), func(b *testing.B) {
+				c := test.cache()
+				for k := range size {
+					c.Put(k, k)
+				}
+
+				var wg sync.WaitGroup
+				b.ResetTimer()
+				for g := range goroutines {
+					// b.N split exactly, the first goroutines taking the remainder
+					ops := b.N / goroutines
+					if g < b.N%goroutines {
+						ops++
+					}
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						zipf := rand.NewZipf(rand.New(rand.NewSource(int64(g))), 1.1, 1, keys-1)
+						for range ops {
+							k := int(zipf.Uint64())
+							if _, ok := c.Get(k); !ok {
+								c.Put(k, k)
+							}
+						}
+					}()
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package godatastructures
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+)
+
+// Number of slots in a group, one control byte per slot
+const groupSize = 8
+
+// Control byte values. A full slot stores the low 7 bits of the key hash
+// (h2) with the high bit clear, so a group can be matched 8 slots at a time
+const (
+	ctrlEmpty   byte = 0b1000_0000
+	ctrlDeleted byte = 0b1111_1110
+)
+
+// default load factor, Swiss tables are kept at most 7/8 full
+const swissLoadFactor float64 = 7.0 / 8
+
+// Used for SWAR (SIMD within a register) matching on control words
+const (
+	bitsLSB uint64 = 0x0101010101010101
+	bitsMSB uint64 = 0x8080808080808080
+)
+
+// Eight control bytes packed into a word, byte i for slot i
+type ctrlWord uint64
+
+// Bitset with the high bit set in the byte of each matching slot
+type ctrlMatch uint64
+
+// Group of slots sharing a control word
+type swissGroup[key comparable, val comparable] struct {
+	ctrl  ctrlWord
+	slots [groupSize]MapEntry[key, val]
+}
+
+// Map storage using open addressing over groups of slots, in the
+// style of a Swiss table. Probing is by group, using the control
+// word to find candidate slots without comparing every key
+type swissMap[key comparable, val comparable] struct {
+	groups     []swissGroup[key, val]
+	hasher     Hasher[key]
+	loadFactor float64
+	mutex      sync.RWMutex
+	size       int
+	growthLeft int // number of empty slots that may be filled before a rehash
+}
+
+// constructor
+func newSwissMap[key comparable, val comparable](config mapConfig, hasher Hasher[key]) *swissMap[key, val] {
+	m := swissMap[key, val]{
+		hasher:     hasher,
+		loadFactor: config.loadFactor,
+	}
+	m.groups = m.newGroups(m.groupsFor(config.minCapacity))
+	return &m
+}
+
+// Returns the number of key-value mappings
+func (m *swissMap[key, val]) len() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.size
+}
+
+// Returns the value mapped to the key
+// boolean ok indicates whether the value was present
+func (m *swissMap[key, val]) get(k key) (value val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	g, s, ok := m.find(k, m.hasher(k))
+	if ok {
+		value = m.groups[g].slots[s].value
+	}
+	return
+}
+
+// Maps the value to the key, replacing any existing value
+func (m *swissMap[key, val]) put(k key, v val) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h := m.hasher(k)
+	if g, s, ok := m.find(k, h); ok {
+		m.groups[g].slots[s].value = v
+		return
+	}
+	m.insert(k, v, h)
+}
+
+// Removes the mapping for the key if present
+func (m *swissMap[key, val]) remove(k key) (ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	g, s, ok := m.find(k, m.hasher(k))
+	if ok {
+		m.delete(g, s)
+	}
+	return
+}
+
+// Replaces the mapping for the key with the result of f, holding the
+// write lock throughout. f receives the current value and whether
+// it was present, and returns the new value and whether to keep it
+func (m *swissMap[key, val]) compute(k key, f func(old val, present bool) (v val, keep bool)) (value val, ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h := m.hasher(k)
+	g, s, present := m.find(k, h)
+
+	var old val
+	if present {
+		old = m.groups[g].slots[s].value
+	}
+
+	value, ok = f(old, present)
+
+	switch {
+	case ok && present:
+		m.groups[g].slots[s].value = value
+	case ok && !present:
+		m.insert(k, value, h)
+	case !ok && present:
+		m.delete(g, s)
+	}
+	return
+}
+
+// Removes all of the mappings
+func (m *swissMap[key, val]) clear() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.groups = m.newGroups(len(m.groups))
+	m.size = 0
+}
+
+// Calls yield with a copy of each entry, a group at a time
+func (m *swissMap[key, val]) entries(yield func(MapEntry[key, val]) bool) {
+	m.mutex.RLock()
+	groups := m.groups // a rehash replaces, rather than modifies, the groups
+	m.mutex.RUnlock()
+
+	var entries []MapEntry[key, val]
+	for g := range groups {
+		entries = entries[:0]
+
+		m.mutex.RLock()
+		for match := groups[g].ctrl.matchFull(); match != 0; match = match.removeFirst() {
+			entries = append(entries, groups[g].slots[match.first()])
+		}
+		m.mutex.RUnlock()
+
+		for _, e := range entries {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Returns a copy of every entry, under the read lock
+func (m *swissMap[key, val]) snapshot() []MapEntry[key, val] {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	entries := make([]MapEntry[key, val], 0, m.size)
+	for g := range m.groups {
+		for match := m.groups[g].ctrl.matchFull(); match != 0; match = match.removeFirst() {
+			entries = append(entries, m.groups[g].slots[match.first()])
+		}
+	}
+	return entries
+}
+
+// Reports whether each key is present, under the read lock
+func (m *swissMap[key, val]) containsEach(keys []key) []bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	present := make([]bool, len(keys))
+	for i, k := range keys {
+		_, _, present[i] = m.find(k, m.hasher(k))
+	}
+	return present
+}
+
+// Used internally to find the group and slot holding the key
+// Must be called with the lock held
+func (m *swissMap[key, val]) find(k key, h uint64) (group int, slot int, ok bool) {
+	h1, h2 := splitHash(h)
+
+	for p := newProbe(h1, len(m.groups)); ; p.next() {
+		g := &m.groups[p.group]
+		for match := g.ctrl.matchH2(h2); match != 0; match = match.removeFirst() {
+			s := match.first()
+			if g.slots[s].key == k {
+				return p.group, s, true
+			}
+		}
+		// an empty slot ends every probe sequence passing through this group
+		if g.ctrl.matchEmpty() != 0 {
+			return
+		}
+	}
+}
+
+// Used internally to add a key known not to be present
+// Must be called with the write lock held
+func (m *swissMap[key, val]) insert(k key, v val, h uint64) {
+	h1, h2 := splitHash(h)
+
+	for p := newProbe(h1, len(m.groups)); ; p.next() {
+		g := &m.groups[p.group]
+		match := g.ctrl.matchEmptyOrDeleted()
+		if match == 0 {
+			continue
+		}
+
+		s := match.first()
+		if g.ctrl.get(s) == ctrlEmpty {
+			if m.growthLeft == 0 {
+				m.rehash()
+				m.insert(k, v, h)
+				return
+			}
+			m.growthLeft--
+		}
+
+		g.ctrl.set(s, h2)
+		g.slots[s] = MapEntry[key, val]{key: k, value: v}
+		m.size++
+		return
+	}
+}
+
+// Used internally to empty a full slot
+// Must be called with the write lock held
+func (m *swissMap[key, val]) delete(g int, s int) {
+	group := &m.groups[g]
+	group.slots[s] = MapEntry[key, val]{}
+
+	// A group that still has an empty slot has never been full, so no
+	// probe has passed through it and the slot can be reused freely.
+	// Otherwise a tombstone keeps later probe sequences intact
+	if group.ctrl.matchEmpty() != 0 {
+		group.ctrl.set(s, ctrlEmpty)
+		m.growthLeft++
+	} else {
+		group.ctrl.set(s, ctrlDeleted)
+	}
+	m.size--
+}
+
+// Used internally to rebuild the table when it runs out of empty slots,
+// doubling it unless enough of the used slots are tombstones
+// Must be called with the write lock held
+func (m *swissMap[key, val]) rehash() {
+	numGroups := len(m.groups)
+	if m.size >= m.maxLoad(numGroups)/2 {
+		numGroups *= 2
+	}
+
+	old := m.groups
+	m.groups = m.newGroups(numGroups)
+	m.size = 0
+
+	for g := range old {
+		for match := old[g].ctrl.matchFull(); match != 0; match = match.removeFirst() {
+			e := old[g].slots[match.first()]
+			m.insert(e.key, e.value, m.hasher(e.key))
+		}
+	}
+}
+
+// Used internally to allocate empty groups and reset growthLeft
+func (m *swissMap[key, val]) newGroups(numGroups int) []swissGroup[key, val] {
+	groups := make([]swissGroup[key, val], numGroups)
+	for g := range groups {
+		groups[g].ctrl = ctrlWord(bitsLSB * uint64(ctrlEmpty))
+	}
+	m.growthLeft = m.maxLoad(numGroups)
+	return groups
+}
+
+// Number of groups, a power of two, needed to hold capacity entries
+func (m *swissMap[key, val]) groupsFor(capacity int) int {
+	slots := int(math.Ceil(float64(capacity) / m.loadFactor))
+	groups := (slots + groupSize - 1) / groupSize
+	if groups <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(groups-1))
+}
+
+// Maximum number of full slots in a table of numGroups groups.
+// At least one slot is always left empty to terminate probing
+func (m *swissMap[key, val]) maxLoad(numGroups int) int {
+	slots := numGroups * groupSize
+	return max(1, min(int(float64(slots)*m.loadFactor), slots-1))
+}
+
+// Split a hash into the probe start (h1) and the control byte (h2)
+func splitHash(h uint64) (h1 uint64, h2 byte) {
+	return h >> 7, byte(h & 0x7f)
+}
+
+// Triangular probe sequence over a power of two number of groups,
+// which visits every group exactly once
+type probe struct {
+	group  int
+	mask   int
+	stride int
+}
+
+// constructor
+func newProbe(h1 uint64, numGroups int) probe {
+	mask := numGroups - 1
+	return probe{group: int(h1) & mask, mask: mask}
+}
+
+// Move to the next group in the sequence
+func (p *probe) next() {
+	p.stride++
+	p.group = (p.group + p.stride) & p.mask
+}
+
+// Get the control byte of a slot
+func (c ctrlWord) get(slot int) byte {
+	return byte(c >> (slot * 8))
+}
+
+// Set the control byte of a slot
+func (c *ctrlWord) set(slot int, b byte) {
+	shift := slot * 8
+	*c = *c&^(0xff<<shift) | ctrlWord(b)<<shift
+}
+
+// Match the slots whose control byte is h2. May report false
+// positives, which are eliminated by comparing keys
+func (c ctrlWord) matchH2(h2 byte) ctrlMatch {
+	v := uint64(c) ^ (bitsLSB * uint64(h2))
+	return ctrlMatch((v - bitsLSB) &^ v & bitsMSB)
+}
+
+// Match the empty slots. Empty has bit 1 clear where deleted has it set
+func (c ctrlWord) matchEmpty() ctrlMatch {
+	return ctrlMatch(uint64(c) &^ (uint64(c) << 6) & bitsMSB)
+}
+
+// Match the empty and deleted slots, which have the high bit set
+func (c ctrlWord) matchEmptyOrDeleted() ctrlMatch {
+	return ctrlMatch(uint64(c) & bitsMSB)
+}
+
+// Match the full slots, which have the high bit clear
+func (c ctrlWord) matchFull() ctrlMatch {
+	return ctrlMatch(^uint64(c) & bitsMSB)
+}
+
+// Get the slot of the first match
+func (m ctrlMatch) first() int {
+	return bits.TrailingZeros64(uint64(m)) >> 3
+}
+
+// Remove the first match
+func (m ctrlMatch) removeFirst() ctrlMatch {
+	return m & (m - 1)
+}
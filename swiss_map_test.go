@@ -0,0 +1,174 @@
+package godatastructures
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSwissControlWord(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test control word matching")
+	{
+		var c ctrlWord
+		for s := range groupSize {
+			c.set(s, ctrlEmpty)
+		}
+		c.set(1, 0x12)
+		c.set(3, ctrlDeleted)
+		c.set(6, 0x12)
+		c.set(7, 0x7f)
+
+		tests := []struct {
+			name  string
+			match ctrlMatch
+			slots []int
+		}{
+			{name: "h2", match: c.matchH2(0x12), slots: []int{1, 6}},
+			{name: "empty", match: c.matchEmpty(), slots: []int{0, 2, 4, 5}},
+			{name: "empty or deleted", match: c.matchEmptyOrDeleted(), slots: []int{0, 2, 3, 4, 5}},
+			{name: "full", match: c.matchFull(), slots: []int{1, 6, 7}},
+		}
+
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When matching %v slots", i, test.name)
+
+			var slots []int
+			for m := test.match; m != 0; m = m.removeFirst() {
+				slots = append(slots, m.first())
+			}
+			if len(slots) != len(test.slots) {
+				t.Errorf("\t%d\t Expected slots %v : %v", i, test.slots, slots)
+				continue
+			}
+			for s := range slots {
+				if slots[s] != test.slots[s] {
+					t.Errorf("\t%d\t Expected slots %v : %v", i, test.slots, slots)
+				}
+			}
+		}
+
+		if c.get(3) != ctrlDeleted || c.get(7) != 0x7f {
+			t.Errorf("\t Control bytes were not stored %x", uint64(c))
+		}
+	}
+}
+
+func TestSwissMap(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test open addressing against the builtin map")
+	{
+		m := NewMap[int, int](0, WithBackend(OpenAddressingBackend))
+		reference := map[int]int{}
+
+		// a small key space forces repeated insert and delete into the same
+		// groups, exercising tombstones and rehashing at the same size
+		for n := range 20000 {
+			k := rand.Intn(500)
+			switch rand.Intn(3) {
+			case 0, 1:
+				m.Put(k, n)
+				reference[k] = n
+			case 2:
+				_, present := reference[k]
+				if m.Remove(k) != present {
+					t.Fatalf("\t Remove of %v expected %v", k, present)
+				}
+				delete(reference, k)
+			}
+		}
+
+		if m.Size() != len(reference) {
+			t.Errorf("\t Size expected %v : %v", len(reference), m.Size())
+		}
+		for k, v := range reference {
+			if got, ok := m.Get(k); !ok || got != v {
+				t.Errorf("\t Get %v expected %v : %v", k, v, got)
+			}
+		}
+		for k, v := range m.All() {
+			if reference[k] != v {
+				t.Errorf("\t All yielded unexpected mapping %v : %v", k, v)
+			}
+		}
+
+		s := m.store.(*swissMap[int, int])
+		if len(s.groups)&(len(s.groups)-1) != 0 {
+			t.Errorf("\t Number of groups should be a power of two : %v", len(s.groups))
+		}
+		if s.size > s.maxLoad(len(s.groups)) {
+			t.Errorf("\t Table exceeded the maximum load %v : %v", s.maxLoad(len(s.groups)), s.size)
+		}
+	}
+}
+
+// Benchmark the Map backends against each other and the builtin map
+func BenchmarkMapBackends(b *testing.B) {
+	const size = 1 << 16
+
+	keys := make([]string, size)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	for _, backend := range mapBackends {
+		b.Run("Put/"+backend.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := range b.N {
+				if i%size == 0 {
+					b.StopTimer()
+					m := NewMap[string, int](1, WithBackend(backend.backend))
+					b.StartTimer()
+					for n := range min(size, b.N-i) {
+						m.Put(keys[n], n)
+					}
+				}
+			}
+		})
+		b.Run("Get/"+backend.name, func(b *testing.B) {
+			m := NewMap[string, int](size, WithBackend(backend.backend))
+			for i, k := range keys {
+				m.Put(k, i)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				m.Get(keys[i%size])
+			}
+		})
+	}
+
+	b.Run("Put/builtin", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := range b.N {
+			if i%size == 0 {
+				b.StopTimer()
+				m := map[string]int{}
+				mutex := sync.RWMutex{}
+				b.StartTimer()
+				for n := range min(size, b.N-i) {
+					mutex.Lock()
+					m[keys[n]] = n
+					mutex.Unlock()
+				}
+			}
+		}
+	})
+	b.Run("Get/builtin", func(b *testing.B) {
+		m := map[string]int{}
+		mutex := sync.RWMutex{}
+		for i, k := range keys {
+			m[k] = i
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := range b.N {
+			mutex.RLock()
+			_ = m[keys[i%size]]
+			mutex.RUnlock()
+		}
+	})
+}
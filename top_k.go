@@ -0,0 +1,112 @@
+package godatastructures
+
+import (
+	"iter"
+	"slices"
+)
+
+// Keeps the k largest values offered, by the supplied compare function,
+// in a Heap whose top is the smallest value kept, so a new value need
+// only be compared with that one and replaces it in O(log k).
+// Offer and Merge use the mutex of the heap to check and replace atomically
+type TopK[val any] struct {
+	k    int
+	heap *Heap[val]
+}
+
+// constructor
+// k must be at least 1
+func NewTopK[val any](k int, f func(val, val) int) (top *TopK[val], ok bool) {
+	if k < 1 {
+		return top, false
+	}
+
+	top = &TopK[val]{
+		k:    k,
+		heap: NewHeap(k, f),
+	}
+	return top, true
+}
+
+// Return the number of values kept once full
+func (t *TopK[val]) K() int {
+	return t.k
+}
+
+// Return the number of values currently kept
+func (t *TopK[val]) Size() int {
+	return t.heap.Size()
+}
+
+// Offer a value, which is kept if fewer than k values are kept, or it is
+// larger than the smallest of them, which it then replaces
+// Returns whether the value was kept
+func (t *TopK[val]) Offer(v val) bool {
+	t.heap.mutex.Lock()
+	defer t.heap.mutex.Unlock()
+
+	return t.offer(v)
+}
+
+// Offer every value kept by the other TopK, which is left unchanged.
+// The other's values are copied under its own lock, so the locks of
+// both are never held together, and partial results from different
+// goroutines can be merged concurrently
+func (t *TopK[val]) Merge(other *TopK[val]) {
+	values := other.snapshot()
+
+	t.heap.mutex.Lock()
+	defer t.heap.mutex.Unlock()
+
+	for _, v := range values {
+		t.offer(v)
+	}
+}
+
+// Return the values kept, largest first
+func (t *TopK[val]) Items() []val {
+	values := t.snapshot()
+	slices.SortFunc(values, func(a, b val) int {
+		return t.heap.compare(b, a)
+	})
+	return values
+}
+
+// Return an iterator over the values kept, largest first, copied when
+// iteration starts
+func (t *TopK[val]) All() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		for _, v := range t.Items() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Used internally to offer a value
+// Must be called with the write lock held
+func (t *TopK[val]) offer(v val) bool {
+	if len(t.heap.slice) < t.k {
+		t.heap.put(v)
+		return true
+	}
+	if t.heap.compare(v, t.heap.slice[0].value) <= 0 {
+		return false
+	}
+	t.heap.slice[0].value = v
+	t.heap.bubbleDown(0)
+	return true
+}
+
+// Used internally to copy the values kept, in heap order
+func (t *TopK[val]) snapshot() []val {
+	t.heap.mutex.RLock()
+	defer t.heap.mutex.RUnlock()
+
+	values := make([]val, len(t.heap.slice))
+	for i, handle := range t.heap.slice {
+		values[i] = handle.value
+	}
+	return values
+}
@@ -0,0 +1,70 @@
+package godatastructures
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test TopK on a stream of values")
+	{
+		if _, ok := NewTopK(0, SortAscendingInt); ok {
+			t.Errorf("\t NewTopK should reject k below 1")
+		}
+
+		top, _ := NewTopK(3, SortAscendingInt)
+		if top.K() != 3 {
+			t.Errorf("\t K expected 3 : %v", top.K())
+		}
+		if items := top.Items(); len(items) != 0 {
+			t.Errorf("\t Items on empty TopK expected none : %v", items)
+		}
+
+		for _, v := range []int{5, 1, 8} {
+			if !top.Offer(v) {
+				t.Errorf("\t Offer %v should be kept below k values", v)
+			}
+		}
+		if top.Offer(1) {
+			t.Errorf("\t Offer of a value no larger than the smallest kept should not be kept")
+		}
+		if !top.Offer(6) {
+			t.Errorf("\t Offer of a larger value should be kept")
+		}
+		expected := []int{8, 6, 5}
+		if items := top.Items(); !slices.Equal(items, expected) {
+			t.Errorf("\t Items expected %v : %v", expected, items)
+		}
+		if values := slices.Collect(top.All()); !slices.Equal(values, expected) {
+			t.Errorf("\t All expected %v : %v", expected, values)
+		}
+	}
+
+	t.Log("Given the need to test merging partial TopKs from several goroutines")
+	{
+		data := rand.New(rand.NewSource(1)).Perm(10000)
+		total, _ := NewTopK(10, SortDescendingInt) // keeps the smallest
+		wg := sync.WaitGroup{}
+		for part := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				partial, _ := NewTopK(10, SortDescendingInt)
+				for i := part; i < len(data); i += 8 {
+					partial.Offer(data[i])
+				}
+				total.Merge(partial)
+			}()
+		}
+		wg.Wait()
+
+		expected := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		if items := total.Items(); !slices.Equal(items, expected) {
+			t.Errorf("\t Merged Items expected %v : %v", expected, items)
+		}
+	}
+}
@@ -0,0 +1,569 @@
+package godatastructures
+
+import (
+	"iter"
+	"sync"
+)
+
+// Sorted map, ordered by the supplied compare function, backed by a
+// left-leaning red-black tree. Each node records the size of its subtree
+// so rank and select run in O(log n)
+type TreeMap[key any, val any] struct {
+	root    *treeNode[key, val]
+	compare func(k1, k2 key) int
+	mutex   sync.RWMutex
+}
+
+// Node in the red-black tree
+type treeNode[key any, val any] struct {
+	key   key
+	value val
+	left  *treeNode[key, val]
+	right *treeNode[key, val]
+	red   bool
+	size  int
+}
+
+// constructor
+func NewTreeMap[key any, val any](f func(key, key) int) *TreeMap[key, val] {
+	m := TreeMap[key, val]{
+		compare: f,
+		mutex:   sync.RWMutex{},
+	}
+	return &m
+}
+
+// Returns the number of key-value mappings in this map
+func (m *TreeMap[key, val]) Size() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return nodeSize(m.root)
+}
+
+// Returns the value to which the specified key is mapped
+// boolean ok indicates whether the value was present
+func (m *TreeMap[key, val]) Get(k key) (value val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	n := m.find(k)
+	if n == nil {
+		return
+	}
+	return n.value, true
+}
+
+// Returns true if this map contains a mapping for the specified key
+func (m *TreeMap[key, val]) ContainsKey(k key) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.find(k) != nil
+}
+
+// Associates the specified value with the specified key in this map
+func (m *TreeMap[key, val]) Put(k key, v val) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.root = m.put(m.root, k, v)
+	m.root.red = false
+}
+
+// Removes the mapping for the specified key from this map if present
+func (m *TreeMap[key, val]) Remove(k key) (ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.find(k) == nil {
+		return false
+	}
+	if !isRed(m.root.left) && !isRed(m.root.right) {
+		m.root.red = true
+	}
+	m.root = m.remove(m.root, k)
+	if m.root != nil {
+		m.root.red = false
+	}
+	return true
+}
+
+// Removes all of the mappings from this map
+func (m *TreeMap[key, val]) Clear() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.root = nil
+}
+
+// Returns the mapping with the smallest key
+// boolean ok indicates whether the map was non-empty
+func (m *TreeMap[key, val]) Min() (k key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return entryOf(minNode(m.root))
+}
+
+// Returns the mapping with the largest key
+// boolean ok indicates whether the map was non-empty
+func (m *TreeMap[key, val]) Max() (k key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return entryOf(maxNode(m.root))
+}
+
+// Removes and returns the mapping with the smallest key
+// boolean ok indicates whether the map was non-empty
+func (m *TreeMap[key, val]) PollFirst() (k key, v val, ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	k, v, ok = entryOf(minNode(m.root))
+	if !ok {
+		return
+	}
+	if !isRed(m.root.left) && !isRed(m.root.right) {
+		m.root.red = true
+	}
+	m.root = removeMin(m.root)
+	if m.root != nil {
+		m.root.red = false
+	}
+	return
+}
+
+// Removes and returns the mapping with the largest key
+// boolean ok indicates whether the map was non-empty
+func (m *TreeMap[key, val]) PollLast() (k key, v val, ok bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	k, v, ok = entryOf(maxNode(m.root))
+	if !ok {
+		return
+	}
+	if !isRed(m.root.left) && !isRed(m.root.right) {
+		m.root.red = true
+	}
+	m.root = removeMax(m.root)
+	if m.root != nil {
+		m.root.red = false
+	}
+	return
+}
+
+// Returns the mapping with the largest key less than or equal to k
+// boolean ok indicates whether such a key exists
+func (m *TreeMap[key, val]) Floor(k key) (fk key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return entryOf(m.floor(k, true))
+}
+
+// Returns the mapping with the smallest key greater than or equal to k
+// boolean ok indicates whether such a key exists
+func (m *TreeMap[key, val]) Ceiling(k key) (ck key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return entryOf(m.ceiling(k, true))
+}
+
+// Returns the mapping with the largest key strictly less than k
+// boolean ok indicates whether such a key exists
+func (m *TreeMap[key, val]) Lower(k key) (lk key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return entryOf(m.floor(k, false))
+}
+
+// Returns the mapping with the smallest key strictly greater than k
+// boolean ok indicates whether such a key exists
+func (m *TreeMap[key, val]) Higher(k key) (hk key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return entryOf(m.ceiling(k, false))
+}
+
+// Returns the number of keys strictly less than k
+func (m *TreeMap[key, val]) Rank(k key) int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	rank := 0
+	n := m.root
+	for n != nil {
+		c := m.compare(k, n.key)
+		switch {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			rank += nodeSize(n.left) + 1
+			n = n.right
+		default:
+			return rank + nodeSize(n.left)
+		}
+	}
+	return rank
+}
+
+// Returns the mapping with the given rank, ie the i'th smallest key
+// counting from 0. boolean ok is false if i is out of range
+func (m *TreeMap[key, val]) Select(i int) (k key, v val, ok bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if i < 0 || i >= nodeSize(m.root) {
+		return
+	}
+	n := m.root
+	for {
+		left := nodeSize(n.left)
+		switch {
+		case i < left:
+			n = n.left
+		case i > left:
+			i -= left + 1
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+}
+
+// Returns an iterator over the mappings in ascending key order.
+// Each step looks up the next key under the read lock in O(log n), so
+// the loop body may safely modify the map. Mappings present throughout
+// iteration are yielded exactly once
+func (m *TreeMap[key, val]) All() iter.Seq2[key, val] {
+	return m.ascend(nil, nil)
+}
+
+// Returns an iterator over the mappings in descending key order,
+// with the same consistency guarantees as All
+func (m *TreeMap[key, val]) Backward() iter.Seq2[key, val] {
+	return func(yield func(key, val) bool) {
+		m.mutex.RLock()
+		k, v, ok := entryOf(maxNode(m.root))
+		m.mutex.RUnlock()
+
+		for ok {
+			if !yield(k, v) {
+				return
+			}
+			m.mutex.RLock()
+			k, v, ok = entryOf(m.floor(k, false))
+			m.mutex.RUnlock()
+		}
+	}
+}
+
+// Returns an iterator over the mappings with keys greater than or equal
+// to from, in ascending order, with the same consistency guarantees as All
+func (m *TreeMap[key, val]) RangeFrom(from key) iter.Seq2[key, val] {
+	return m.ascend(&from, nil)
+}
+
+// Returns an iterator over the mappings with keys strictly less than to,
+// in ascending order, with the same consistency guarantees as All
+func (m *TreeMap[key, val]) RangeTo(to key) iter.Seq2[key, val] {
+	return m.ascend(nil, &to)
+}
+
+// Returns an iterator over the mappings with keys in [from, to),
+// in ascending order, with the same consistency guarantees as All
+func (m *TreeMap[key, val]) Range(from key, to key) iter.Seq2[key, val] {
+	return m.ascend(&from, &to)
+}
+
+// Returns an iterator over the keys in ascending order
+func (m *TreeMap[key, val]) Keys() iter.Seq[key] {
+	return func(yield func(key) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the values in ascending key order
+func (m *TreeMap[key, val]) Values() iter.Seq[val] {
+	return func(yield func(val) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates in ascending order over keys in [from, to),
+// where a nil bound is unbounded
+func (m *TreeMap[key, val]) ascend(from *key, to *key) iter.Seq2[key, val] {
+	return func(yield func(key, val) bool) {
+		m.mutex.RLock()
+		var k key
+		var v val
+		var ok bool
+		if from == nil {
+			k, v, ok = entryOf(minNode(m.root))
+		} else {
+			k, v, ok = entryOf(m.ceiling(*from, true))
+		}
+		m.mutex.RUnlock()
+
+		for ok {
+			if to != nil && m.compare(k, *to) >= 0 {
+				return
+			}
+			if !yield(k, v) {
+				return
+			}
+			m.mutex.RLock()
+			k, v, ok = entryOf(m.ceiling(k, false))
+			m.mutex.RUnlock()
+		}
+	}
+}
+
+// Returns the node for key k, or nil
+func (m *TreeMap[key, val]) find(k key) *treeNode[key, val] {
+	n := m.root
+	for n != nil {
+		c := m.compare(k, n.key)
+		switch {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// Returns the node with the largest key less than k,
+// or equal to k if inclusive
+func (m *TreeMap[key, val]) floor(k key, inclusive bool) (best *treeNode[key, val]) {
+	n := m.root
+	for n != nil {
+		c := m.compare(k, n.key)
+		if c > 0 || (c == 0 && inclusive) {
+			best = n
+			if c == 0 {
+				return
+			}
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return
+}
+
+// Returns the node with the smallest key greater than k,
+// or equal to k if inclusive
+func (m *TreeMap[key, val]) ceiling(k key, inclusive bool) (best *treeNode[key, val]) {
+	n := m.root
+	for n != nil {
+		c := m.compare(k, n.key)
+		if c < 0 || (c == 0 && inclusive) {
+			best = n
+			if c == 0 {
+				return
+			}
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return
+}
+
+// Inserts or replaces the mapping in the subtree rooted at h
+func (m *TreeMap[key, val]) put(h *treeNode[key, val], k key, v val) *treeNode[key, val] {
+	if h == nil {
+		return &treeNode[key, val]{key: k, value: v, red: true, size: 1}
+	}
+
+	c := m.compare(k, h.key)
+	switch {
+	case c < 0:
+		h.left = m.put(h.left, k, v)
+	case c > 0:
+		h.right = m.put(h.right, k, v)
+	default:
+		h.value = v
+	}
+	return balance(h)
+}
+
+// Removes key k, which must be present, from the subtree rooted at h
+func (m *TreeMap[key, val]) remove(h *treeNode[key, val], k key) *treeNode[key, val] {
+	if m.compare(k, h.key) < 0 {
+		if !isRed(h.left) && !isRed(h.left.left) {
+			h = moveRedLeft(h)
+		}
+		h.left = m.remove(h.left, k)
+		return balance(h)
+	}
+
+	if isRed(h.left) {
+		h = rotateRight(h)
+	}
+	if m.compare(k, h.key) == 0 && h.right == nil {
+		return nil
+	}
+	if !isRed(h.right) && !isRed(h.right.left) {
+		h = moveRedRight(h)
+	}
+	if m.compare(k, h.key) == 0 {
+		successor := minNode(h.right)
+		h.key, h.value = successor.key, successor.value
+		h.right = removeMin(h.right)
+	} else {
+		h.right = m.remove(h.right, k)
+	}
+	return balance(h)
+}
+
+// Removes the smallest key from the subtree rooted at h
+func removeMin[key any, val any](h *treeNode[key, val]) *treeNode[key, val] {
+	if h.left == nil {
+		return nil
+	}
+	if !isRed(h.left) && !isRed(h.left.left) {
+		h = moveRedLeft(h)
+	}
+	h.left = removeMin(h.left)
+	return balance(h)
+}
+
+// Removes the largest key from the subtree rooted at h
+func removeMax[key any, val any](h *treeNode[key, val]) *treeNode[key, val] {
+	if isRed(h.left) {
+		h = rotateRight(h)
+	}
+	if h.right == nil {
+		return nil
+	}
+	if !isRed(h.right) && !isRed(h.right.left) {
+		h = moveRedRight(h)
+	}
+	h.right = removeMax(h.right)
+	return balance(h)
+}
+
+// Restores the left-leaning red-black invariants at h
+// and recomputes its subtree size
+func balance[key any, val any](h *treeNode[key, val]) *treeNode[key, val] {
+	if isRed(h.right) && !isRed(h.left) {
+		h = rotateLeft(h)
+	}
+	if isRed(h.left) && isRed(h.left.left) {
+		h = rotateRight(h)
+	}
+	if isRed(h.left) && isRed(h.right) {
+		flipColours(h)
+	}
+	h.size = nodeSize(h.left) + nodeSize(h.right) + 1
+	return h
+}
+
+// Makes h.left or one of its children red, assuming h is red
+// and both h.left and h.left.left are black
+func moveRedLeft[key any, val any](h *treeNode[key, val]) *treeNode[key, val] {
+	flipColours(h)
+	if isRed(h.right.left) {
+		h.right = rotateRight(h.right)
+		h = rotateLeft(h)
+		flipColours(h)
+	}
+	return h
+}
+
+// Makes h.right or one of its children red, assuming h is red
+// and both h.right and h.right.left are black
+func moveRedRight[key any, val any](h *treeNode[key, val]) *treeNode[key, val] {
+	flipColours(h)
+	if isRed(h.left.left) {
+		h = rotateRight(h)
+		flipColours(h)
+	}
+	return h
+}
+
+func rotateLeft[key any, val any](h *treeNode[key, val]) *treeNode[key, val] {
+	x := h.right
+	h.right = x.left
+	x.left = h
+	x.red = h.red
+	h.red = true
+	x.size = h.size
+	h.size = nodeSize(h.left) + nodeSize(h.right) + 1
+	return x
+}
+
+func rotateRight[key any, val any](h *treeNode[key, val]) *treeNode[key, val] {
+	x := h.left
+	h.left = x.right
+	x.right = h
+	x.red = h.red
+	h.red = true
+	x.size = h.size
+	h.size = nodeSize(h.left) + nodeSize(h.right) + 1
+	return x
+}
+
+func flipColours[key any, val any](h *treeNode[key, val]) {
+	h.red = !h.red
+	h.left.red = !h.left.red
+	h.right.red = !h.right.red
+}
+
+func isRed[key any, val any](n *treeNode[key, val]) bool {
+	return n != nil && n.red
+}
+
+func nodeSize[key any, val any](n *treeNode[key, val]) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func minNode[key any, val any](n *treeNode[key, val]) *treeNode[key, val] {
+	if n == nil {
+		return nil
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func maxNode[key any, val any](n *treeNode[key, val]) *treeNode[key, val] {
+	if n == nil {
+		return nil
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n
+}
+
+// Returns the mapping held by n, with ok false if n is nil
+func entryOf[key any, val any](n *treeNode[key, val]) (k key, v val, ok bool) {
+	if n == nil {
+		return
+	}
+	return n.key, n.value, true
+}
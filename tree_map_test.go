@@ -0,0 +1,312 @@
+package godatastructures
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Checks the left-leaning red-black invariants and subtree sizes,
+// returning the black height
+func checkTree[key any, val any](t *testing.T, n *treeNode[key, val], compare func(key, key) int) int {
+	t.Helper()
+	if n == nil {
+		return 1
+	}
+	if isRed(n.right) {
+		t.Fatalf("\t Right leaning red link at %v", n.key)
+	}
+	if n.red && isRed(n.left) {
+		t.Fatalf("\t Two red links in a row at %v", n.key)
+	}
+	if n.left != nil && compare(n.left.key, n.key) >= 0 {
+		t.Fatalf("\t Left child %v out of order with %v", n.left.key, n.key)
+	}
+	if n.right != nil && compare(n.right.key, n.key) <= 0 {
+		t.Fatalf("\t Right child %v out of order with %v", n.right.key, n.key)
+	}
+	if n.size != nodeSize(n.left)+nodeSize(n.right)+1 {
+		t.Fatalf("\t Size of %v is %v, expected %v", n.key, n.size, nodeSize(n.left)+nodeSize(n.right)+1)
+	}
+	left := checkTree(t, n.left, compare)
+	right := checkTree(t, n.right, compare)
+	if left != right {
+		t.Fatalf("\t Unequal black height at %v : %v %v", n.key, left, right)
+	}
+	if !n.red {
+		left++
+	}
+	return left
+}
+
+func TestTreeMap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		data        []int
+		compareFunc func(i, j int) int
+		expected    []int // keys in iteration order
+	}{
+		{
+			data:        []int{50, 20, 80, 10, 30, 70, 90, 20},
+			compareFunc: SortAscendingInt,
+			expected:    []int{10, 20, 30, 50, 70, 80, 90},
+		},
+		{
+			data:        []int{50, 20, 80, 10, 30, 70, 90},
+			compareFunc: SortDescendingInt,
+			expected:    []int{90, 80, 70, 50, 30, 20, 10},
+		},
+		{
+			data:        []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			compareFunc: SortAscendingInt,
+			expected:    []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			data:        []int{42},
+			compareFunc: SortAscendingInt,
+			expected:    []int{42},
+		},
+	}
+
+	t.Log("Given the need to test TreeMap behaviour on sample data")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing source data %v ", i, test.data)
+			{
+				m := NewTreeMap[int, int](test.compareFunc)
+
+				t.Logf("\t%d\t Testing empty TreeMap behaviour", i)
+
+				if _, _, ok := m.Min(); ok {
+					t.Errorf("\t%d\t Min on empty map returned a value", i)
+				}
+				if _, _, ok := m.PollLast(); ok {
+					t.Errorf("\t%d\t PollLast on empty map returned a value", i)
+				}
+				if _, _, ok := m.Select(0); ok {
+					t.Errorf("\t%d\t Select on empty map returned a value", i)
+				}
+
+				for _, v := range test.data {
+					m.Put(v, v*10)
+					checkTree(t, m.root, test.compareFunc)
+				}
+
+				if m.Size() != len(test.expected) {
+					t.Errorf("\t%d\t Size expected %v : %v", i, len(test.expected), m.Size())
+				}
+
+				keys := slices.Collect(m.Keys())
+				if !slices.Equal(keys, test.expected) {
+					t.Errorf("\t%d\t Keys expected %v : %v", i, test.expected, keys)
+				}
+
+				reversed := slices.Clone(test.expected)
+				slices.Reverse(reversed)
+				var backward []int
+				for k := range m.Backward() {
+					backward = append(backward, k)
+				}
+				if !slices.Equal(backward, reversed) {
+					t.Errorf("\t%d\t Backward expected %v : %v", i, reversed, backward)
+				}
+
+				first, last := test.expected[0], test.expected[len(test.expected)-1]
+				if k, v, _ := m.Min(); k != first || v != first*10 {
+					t.Errorf("\t%d\t Min expected %v : %v", i, first, k)
+				}
+				if k, _, _ := m.Max(); k != last {
+					t.Errorf("\t%d\t Max expected %v : %v", i, last, k)
+				}
+
+				t.Logf("\t%d\t Testing rank and select", i)
+
+				for r, k := range test.expected {
+					if m.Rank(k) != r {
+						t.Errorf("\t%d\t Rank of %v expected %v : %v", i, k, r, m.Rank(k))
+					}
+					if s, _, ok := m.Select(r); !ok || s != k {
+						t.Errorf("\t%d\t Select %v expected %v : %v", i, r, k, s)
+					}
+				}
+
+				t.Logf("\t%d\t Testing navigation against a linear scan", i)
+
+				for probe := -5; probe <= 100; probe += 5 {
+					checkNavigation(t, i, m, test.expected, probe, test.compareFunc)
+				}
+
+				t.Logf("\t%d\t Testing range iteration", i)
+
+				if len(test.expected) > 2 {
+					from, to := test.expected[1], test.expected[len(test.expected)-1]
+					got := slices.Collect(keysOf(m.Range(from, to)))
+					want := test.expected[1 : len(test.expected)-1]
+					if !slices.Equal(got, want) {
+						t.Errorf("\t%d\t Range expected %v : %v", i, want, got)
+					}
+					got = slices.Collect(keysOf(m.RangeFrom(from)))
+					if !slices.Equal(got, test.expected[1:]) {
+						t.Errorf("\t%d\t RangeFrom expected %v : %v", i, test.expected[1:], got)
+					}
+					got = slices.Collect(keysOf(m.RangeTo(to)))
+					if !slices.Equal(got, test.expected[:len(test.expected)-1]) {
+						t.Errorf("\t%d\t RangeTo expected %v : %v", i, test.expected[:len(test.expected)-1], got)
+					}
+				}
+
+				t.Logf("\t%d\t Testing PollFirst and PollLast", i)
+
+				if k, _, ok := m.PollFirst(); !ok || k != first {
+					t.Errorf("\t%d\t PollFirst expected %v : %v", i, first, k)
+				}
+				checkTree(t, m.root, test.compareFunc)
+				if len(test.expected) > 1 {
+					if k, _, ok := m.PollLast(); !ok || k != last {
+						t.Errorf("\t%d\t PollLast expected %v : %v", i, last, k)
+					}
+					checkTree(t, m.root, test.compareFunc)
+				}
+
+				m.Clear()
+				if m.Size() != 0 {
+					t.Errorf("\t%d\t Clear should empty the map", i)
+				}
+			}
+		}
+	}
+}
+
+func checkNavigation(t *testing.T, i int, m *TreeMap[int, int], sorted []int, probe int, compare func(int, int) int) {
+	t.Helper()
+
+	var floor, ceiling, lower, higher []int
+	for _, k := range sorted {
+		c := compare(k, probe)
+		if c <= 0 {
+			floor = append(floor, k)
+		}
+		if c < 0 {
+			lower = append(lower, k)
+		}
+		if c >= 0 {
+			ceiling = append(ceiling, k)
+		}
+		if c > 0 {
+			higher = append(higher, k)
+		}
+	}
+
+	check := func(name string, k int, ok bool, want []int, last bool) {
+		if len(want) == 0 {
+			if ok {
+				t.Errorf("\t%d\t %v(%v) expected none : %v", i, name, probe, k)
+			}
+			return
+		}
+		w := want[0]
+		if last {
+			w = want[len(want)-1]
+		}
+		if !ok || k != w {
+			t.Errorf("\t%d\t %v(%v) expected %v : %v", i, name, probe, w, k)
+		}
+	}
+
+	k, _, ok := m.Floor(probe)
+	check("Floor", k, ok, floor, true)
+	k, _, ok = m.Lower(probe)
+	check("Lower", k, ok, lower, true)
+	k, _, ok = m.Ceiling(probe)
+	check("Ceiling", k, ok, ceiling, false)
+	k, _, ok = m.Higher(probe)
+	check("Higher", k, ok, higher, false)
+}
+
+func TestTreeMapRandom(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test TreeMap against a reference map under random operations")
+	{
+		r := rand.New(rand.NewSource(1))
+		m := NewTreeMap[int, int](SortAscendingInt)
+		reference := map[int]int{}
+
+		for n := range 5000 {
+			k := r.Intn(500)
+			switch r.Intn(4) {
+			case 0, 1:
+				m.Put(k, n)
+				reference[k] = n
+			case 2:
+				_, present := reference[k]
+				if m.Remove(k) != present {
+					t.Fatalf("\t Remove(%v) expected %v", k, present)
+				}
+				delete(reference, k)
+			case 3:
+				k, _, ok := m.PollFirst()
+				if ok {
+					delete(reference, k)
+				}
+			}
+			if n%100 == 0 {
+				checkTree(t, m.root, SortAscendingInt)
+			}
+		}
+		checkTree(t, m.root, SortAscendingInt)
+
+		if m.Size() != len(reference) {
+			t.Errorf("\t Size expected %v : %v", len(reference), m.Size())
+		}
+		for k, v := range m.All() {
+			if reference[k] != v {
+				t.Errorf("\t Value for %v expected %v : %v", k, reference[k], v)
+			}
+		}
+	}
+}
+
+func TestTreeMapConcurrent(t *testing.T) {
+	t.Parallel()
+
+	t.Log("Given the need to test TreeMap iteration while it is modified")
+	{
+		m := NewTreeMap[int, int](SortAscendingInt)
+		for k := range 100 {
+			m.Put(k*2, k)
+		}
+
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for k := range 1000 {
+				m.Put(k*2+1, k)
+				m.Remove(k*2 + 1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				prev := -1
+				count := 0
+				for k := range m.Keys() {
+					if k <= prev {
+						t.Errorf("\t Keys out of order %v after %v", k, prev)
+					}
+					prev = k
+					if k%2 == 0 {
+						count++
+					}
+				}
+				if count != 100 {
+					t.Errorf("\t Keys present throughout should be yielded once, got %v", count)
+				}
+			}
+		}()
+		wg.Wait()
+	}
+}
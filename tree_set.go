@@ -0,0 +1,158 @@
+package godatastructures
+
+import "iter"
+
+// Sorted set, ordered by the supplied compare function, built on TreeMap
+type TreeSet[val any] struct {
+	m *TreeMap[val, struct{}]
+}
+
+// constructor
+func NewTreeSet[val any](f func(val, val) int) *TreeSet[val] {
+	s := TreeSet[val]{
+		m: NewTreeMap[val, struct{}](f),
+	}
+	return &s
+}
+
+// Returns the number of elements in this set
+func (s *TreeSet[val]) Size() int {
+	return s.m.Size()
+}
+
+// Add a value to the set
+func (s *TreeSet[val]) Add(v val) {
+	s.m.Put(v, struct{}{})
+}
+
+// Add a slice of values to the set
+func (s *TreeSet[val]) AddSlice(t []val) {
+	for i := range t {
+		s.Add(t[i])
+	}
+}
+
+// Remove a value from the set
+func (s *TreeSet[val]) Remove(v val) (ok bool) {
+	return s.m.Remove(v)
+}
+
+// Return true if the value is in the set
+func (s *TreeSet[val]) Contains(v val) bool {
+	return s.m.ContainsKey(v)
+}
+
+// Removes all of the values from the set
+func (s *TreeSet[val]) Clear() {
+	s.m.Clear()
+}
+
+// Returns the smallest value
+// boolean ok indicates whether the set was non-empty
+func (s *TreeSet[val]) Min() (v val, ok bool) {
+	v, _, ok = s.m.Min()
+	return
+}
+
+// Returns the largest value
+// boolean ok indicates whether the set was non-empty
+func (s *TreeSet[val]) Max() (v val, ok bool) {
+	v, _, ok = s.m.Max()
+	return
+}
+
+// Removes and returns the smallest value
+// boolean ok indicates whether the set was non-empty
+func (s *TreeSet[val]) PollFirst() (v val, ok bool) {
+	v, _, ok = s.m.PollFirst()
+	return
+}
+
+// Removes and returns the largest value
+// boolean ok indicates whether the set was non-empty
+func (s *TreeSet[val]) PollLast() (v val, ok bool) {
+	v, _, ok = s.m.PollLast()
+	return
+}
+
+// Returns the largest value less than or equal to v
+func (s *TreeSet[val]) Floor(v val) (f val, ok bool) {
+	f, _, ok = s.m.Floor(v)
+	return
+}
+
+// Returns the smallest value greater than or equal to v
+func (s *TreeSet[val]) Ceiling(v val) (c val, ok bool) {
+	c, _, ok = s.m.Ceiling(v)
+	return
+}
+
+// Returns the largest value strictly less than v
+func (s *TreeSet[val]) Lower(v val) (l val, ok bool) {
+	l, _, ok = s.m.Lower(v)
+	return
+}
+
+// Returns the smallest value strictly greater than v
+func (s *TreeSet[val]) Higher(v val) (h val, ok bool) {
+	h, _, ok = s.m.Higher(v)
+	return
+}
+
+// Returns the number of values strictly less than v
+func (s *TreeSet[val]) Rank(v val) int {
+	return s.m.Rank(v)
+}
+
+// Returns the i'th smallest value counting from 0
+// boolean ok is false if i is out of range
+func (s *TreeSet[val]) Select(i int) (v val, ok bool) {
+	v, _, ok = s.m.Select(i)
+	return
+}
+
+// Return the values of the set in ascending order in a slice
+func (s *TreeSet[val]) Slice() []val {
+	sl := make([]val, 0, s.Size())
+	for v := range s.All() {
+		sl = append(sl, v)
+	}
+	return sl
+}
+
+// Return an iterator over the values in ascending order, with the
+// same consistency guarantees as TreeMap.All
+func (s *TreeSet[val]) All() iter.Seq[val] {
+	return s.m.Keys()
+}
+
+// Return an iterator over the values in descending order
+func (s *TreeSet[val]) Backward() iter.Seq[val] {
+	return keysOf(s.m.Backward())
+}
+
+// Return an iterator over the values greater than or equal to from
+func (s *TreeSet[val]) RangeFrom(from val) iter.Seq[val] {
+	return keysOf(s.m.RangeFrom(from))
+}
+
+// Return an iterator over the values strictly less than to
+func (s *TreeSet[val]) RangeTo(to val) iter.Seq[val] {
+	return keysOf(s.m.RangeTo(to))
+}
+
+// Return an iterator over the values in [from, to)
+func (s *TreeSet[val]) Range(from val, to val) iter.Seq[val] {
+	return keysOf(s.m.Range(from, to))
+}
+
+// Adapts a key-value iterator to an iterator over its keys
+func keysOf[key any, val any](seq iter.Seq2[key, val]) iter.Seq[key] {
+	return func(yield func(key) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package godatastructures
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTreeSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source      []string
+		compareFunc func(a, b string) int
+		expected    []string
+	}{
+		{
+			source:      []string{"pear", "apple", "fig", "apple", "banana"},
+			compareFunc: strings.Compare,
+			expected:    []string{"apple", "banana", "fig", "pear"},
+		},
+		{
+			source:      []string{"pear", "apple", "fig", "banana"},
+			compareFunc: func(a, b string) int { return strings.Compare(b, a) },
+			expected:    []string{"pear", "fig", "banana", "apple"},
+		},
+		{
+			source:      []string{},
+			compareFunc: strings.Compare,
+			expected:    []string{},
+		},
+	}
+
+	t.Log("Given the need to test TreeSet behaviour")
+	{
+		for i, test := range tests {
+			t.Logf("\tTest: %d\t When testing source data %v ", i, test.source)
+			{
+				s := NewTreeSet(test.compareFunc)
+				s.AddSlice(test.source)
+
+				if s.Size() != len(test.expected) {
+					t.Errorf("\t%d\t Size expected %v : %v", i, len(test.expected), s.Size())
+				}
+				if !slices.Equal(s.Slice(), test.expected) {
+					t.Errorf("\t%d\t Slice expected %v : %v", i, test.expected, s.Slice())
+				}
+
+				if len(test.expected) == 0 {
+					if _, ok := s.Min(); ok {
+						t.Errorf("\t%d\t Min on empty set returned a value", i)
+					}
+					continue
+				}
+
+				for r, v := range test.expected {
+					if !s.Contains(v) {
+						t.Errorf("\t%d\t Set should contain %v", i, v)
+					}
+					if s.Rank(v) != r {
+						t.Errorf("\t%d\t Rank of %v expected %v : %v", i, v, r, s.Rank(v))
+					}
+					if got, _ := s.Select(r); got != v {
+						t.Errorf("\t%d\t Select %v expected %v : %v", i, r, v, got)
+					}
+				}
+
+				if v, _ := s.Higher(test.expected[0]); v != test.expected[1] {
+					t.Errorf("\t%d\t Higher expected %v : %v", i, test.expected[1], v)
+				}
+				if v, _ := s.Lower(test.expected[1]); v != test.expected[0] {
+					t.Errorf("\t%d\t Lower expected %v : %v", i, test.expected[0], v)
+				}
+				if v, _ := s.Floor(test.expected[1]); v != test.expected[1] {
+					t.Errorf("\t%d\t Floor expected %v : %v", i, test.expected[1], v)
+				}
+				if v, _ := s.Ceiling(test.expected[1]); v != test.expected[1] {
+					t.Errorf("\t%d\t Ceiling expected %v : %v", i, test.expected[1], v)
+				}
+
+				got := slices.Collect(s.Range(test.expected[1], test.expected[3]))
+				if !slices.Equal(got, test.expected[1:3]) {
+					t.Errorf("\t%d\t Range expected %v : %v", i, test.expected[1:3], got)
+				}
+				if got := slices.Collect(s.RangeFrom(test.expected[2])); !slices.Equal(got, test.expected[2:]) {
+					t.Errorf("\t%d\t RangeFrom expected %v : %v", i, test.expected[2:], got)
+				}
+				if got := slices.Collect(s.RangeTo(test.expected[2])); !slices.Equal(got, test.expected[:2]) {
+					t.Errorf("\t%d\t RangeTo expected %v : %v", i, test.expected[:2], got)
+				}
+
+				reversed := slices.Clone(test.expected)
+				slices.Reverse(reversed)
+				if got := slices.Collect(s.Backward()); !slices.Equal(got, reversed) {
+					t.Errorf("\t%d\t Backward expected %v : %v", i, reversed, got)
+				}
+
+				if v, _ := s.PollFirst(); v != test.expected[0] {
+					t.Errorf("\t%d\t PollFirst expected %v : %v", i, test.expected[0], v)
+				}
+				if v, _ := s.PollLast(); v != test.expected[len(test.expected)-1] {
+					t.Errorf("\t%d\t PollLast expected %v : %v", i, test.expected[len(test.expected)-1], v)
+				}
+				if !s.Remove(test.expected[1]) || s.Contains(test.expected[1]) {
+					t.Errorf("\t%d\t Remove should remove %v", i, test.expected[1])
+				}
+				if s.Size() != len(test.expected)-3 {
+					t.Errorf("\t%d\t Size expected %v : %v", i, len(test.expected)-3, s.Size())
+				}
+			}
+		}
+	}
+}